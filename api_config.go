@@ -2,18 +2,37 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"net/http"
 	"sync/atomic"
+	"time"
 
+	"github.com/Mielecki/Chirpy/internal/auth"
 	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/Mielecki/Chirpy/internal/entitlements"
+	"github.com/Mielecki/Chirpy/internal/moderation"
+	"github.com/Mielecki/Chirpy/internal/storage"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type apiConfig struct {
 	fileserverHits atomic.Int32
+	db *sql.DB
 	database *database.Queries
 	platform string
 	secret string
 	polkaKey string
+	polkaSecrets []string
+	webhookClient *http.Client
+	entitlements entitlements.Config
+	trashRetention time.Duration
+	storage storage.BlobStore
+	mediaFiles http.Handler
+	adminKey string
+	moderation atomic.Pointer[moderation.Pipeline]
+	moderationFileRules []moderation.Rule
 }
 
 func (cfg *apiConfig) handlerReset(w http.ResponseWriter, req *http.Request) {
@@ -27,4 +46,39 @@ func (cfg *apiConfig) handlerReset(w http.ResponseWriter, req *http.Request) {
 	}
 	cfg.fileserverHits.Store(0)
 	w.WriteHeader(http.StatusOK)
-}
\ No newline at end of file
+}
+
+// optionalUserID is for endpoints that work anonymously but personalize the response when a token is sent.
+func (cfg *apiConfig) optionalUserID(req *http.Request) (uuid.NullUUID, error) {
+	if req.Header.Get("Authorization") == "" {
+		return uuid.NullUUID{}, nil
+	}
+
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+
+	return uuid.NullUUID{UUID: userID, Valid: true}, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func (cfg *apiConfig) checkAdminKey(req *http.Request) error {
+	key, err := auth.GetAPIKey(req.Header)
+	if err != nil {
+		return err
+	}
+	if !auth.KeysMatch(key, cfg.adminKey) {
+		return errors.New("invalid admin key")
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/Mielecki/Chirpy/internal/auth"
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/Mielecki/Chirpy/internal/media"
+	"github.com/google/uuid"
+)
+
+const (
+	maxAttachmentSize   = 5 << 20
+	maxAttachmentPixels = 40_000_000
+	maxChirpAttachments = 4
+	thumbnailSize       = 320
+)
+
+var attachmentExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+}
+
+type Attachment struct {
+	ID           uuid.UUID `json:"id"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	ContentType  string    `json:"content_type"`
+	Width        int32     `json:"width"`
+	Height       int32     `json:"height"`
+}
+
+func (cfg *apiConfig) attachmentFromDatabase(attachment database.Attachment) Attachment {
+	return Attachment{
+		ID:           attachment.ID,
+		URL:          cfg.storage.URL(attachment.StorageKey),
+		ThumbnailURL: cfg.storage.URL(attachment.ThumbnailKey),
+		ContentType:  attachment.ContentType,
+		Width:        attachment.Width,
+		Height:       attachment.Height,
+	}
+}
+
+func (cfg *apiConfig) handlerUploadAttachment(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	// Leave some room for the multipart envelope around the file itself.
+	req.Body = http.MaxBytesReader(w, req.Body, maxAttachmentSize+1<<20)
+	file, _, err := req.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "File is too large", err)
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Couldn't read file", err)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxAttachmentSize+1))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't read file", err)
+		return
+	}
+	if len(data) > maxAttachmentSize {
+		respondWithError(w, http.StatusRequestEntityTooLarge, "File is too large", nil)
+		return
+	}
+
+	// The declared Content-Type is up to the client, so trust only the file contents.
+	contentType := http.DetectContentType(data)
+	extension, ok := attachmentExtensions[contentType]
+	if !ok {
+		respondWithError(w, http.StatusUnsupportedMediaType, "Unsupported file type", nil)
+		return
+	}
+
+	imageConfig, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid image", err)
+		return
+	}
+	if imageConfig.Width*imageConfig.Height > maxAttachmentPixels {
+		respondWithError(w, http.StatusBadRequest, "Image dimensions are too large", nil)
+		return
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid image", err)
+		return
+	}
+
+	thumbnail := bytes.Buffer{}
+	if err := png.Encode(&thumbnail, media.Thumbnail(img, thumbnailSize)); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Creating thumbnail error", err)
+		return
+	}
+
+	attachmentID := uuid.New()
+	storageKey := "attachments/" + attachmentID.String() + extension
+	thumbnailKey := "thumbnails/" + attachmentID.String() + ".png"
+
+	if err := cfg.storage.Put(req.Context(), storageKey, bytes.NewReader(data)); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Storing file error", err)
+		return
+	}
+	if err := cfg.storage.Put(req.Context(), thumbnailKey, &thumbnail); err != nil {
+		cfg.deleteBlobs(req.Context(), storageKey)
+		respondWithError(w, http.StatusInternalServerError, "Storing file error", err)
+		return
+	}
+
+	attachment, err := cfg.database.CreateAttachment(req.Context(), database.CreateAttachmentParams{
+		ID:           attachmentID,
+		UserID:       userID,
+		ContentType:  contentType,
+		SizeBytes:    int64(len(data)),
+		Width:        int32(imageConfig.Width),
+		Height:       int32(imageConfig.Height),
+		StorageKey:   storageKey,
+		ThumbnailKey: thumbnailKey,
+	})
+	if err != nil {
+		cfg.deleteBlobs(req.Context(), storageKey, thumbnailKey)
+		respondWithError(w, http.StatusInternalServerError, "Creating attachment error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, cfg.attachmentFromDatabase(attachment))
+}
+
+// Blobs are served only to viewers who can see the chirp they are attached to; an
+// attachment that isn't on a chirp yet is visible to its uploader alone.
+func (cfg *apiConfig) handlerGetMedia(w http.ResponseWriter, req *http.Request) {
+	viewerID, err := cfg.optionalUserID(req)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	attachment, err := cfg.database.GetAttachmentByKey(req.Context(), req.PathValue("key"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No attachment error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Getting attachment error", err)
+		return
+	}
+
+	if !attachment.ChirpID.Valid {
+		if !viewerID.Valid || viewerID.UUID != attachment.UserID {
+			respondWithError(w, http.StatusNotFound, "No attachment error", nil)
+			return
+		}
+	} else if _, err := cfg.getVisibleChirp(req.Context(), attachment.ChirpID.UUID, viewerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No attachment error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Getting chirp error", err)
+		return
+	}
+
+	cfg.mediaFiles.ServeHTTP(w, req)
+}
+
+// deleteBlobs is best effort: the database is the source of truth, a leftover file only costs disk space.
+func (cfg *apiConfig) deleteBlobs(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := cfg.storage.Delete(ctx, key); err != nil {
+			log.Printf("Deleting blob %s: %s", key, err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/auth"
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerBookmark(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing chirpID error", err)
+		return
+	}
+
+	if _, err := cfg.getPublishedChirp(req.Context(), chirpID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No chirp error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Getting chirp error", err)
+		return
+	}
+
+	if err := cfg.database.CreateBookmark(req.Context(), database.CreateBookmarkParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Bookmarking error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerRemoveBookmark(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing chirpID error", err)
+		return
+	}
+
+	if err := cfg.database.DeleteBookmark(req.Context(), database.DeleteBookmarkParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Removing bookmark error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Bookmarks are private, so the list is always the caller's own.
+func (cfg *apiConfig) handlerGetBookmarks(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	pageSize, err := parsePageSize(req.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := decodeCursor(req.URL.Query().Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	rows, err := cfg.database.ListBookmarkedChirps(req.Context(), database.ListBookmarkedChirpsParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        pageSize + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting bookmarks error", err)
+		return
+	}
+
+	rows, nextCursor := nextPage(rows, pageSize, func(row database.ListBookmarkedChirpsRow) (time.Time, uuid.UUID) {
+		return row.BookmarkedAt, row.Chirp.ID
+	})
+
+	chirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, row.Chirp)
+	}
+
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), uuid.NullUUID{UUID: userID, Valid: true}, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting bookmarks error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ChirpPage{
+		Chirps:     chirpsJSON,
+		NextCursor: nextCursor,
+	})
+}
@@ -1,28 +1,73 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
-	"sort"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/Mielecki/Chirpy/internal/auth"
+	"github.com/Mielecki/Chirpy/internal/chirptext"
 	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/Mielecki/Chirpy/internal/entitlements"
+	"github.com/Mielecki/Chirpy/internal/moderation"
 	"github.com/google/uuid"
 )
 
+const (
+	// maxChirpBytes is well above what any plan's length limit allows, even in multi-byte scripts.
+	maxChirpBytes = 16 << 10
+	// maxChirpRequestBytes leaves room for JSON escaping and the fields around the body.
+	maxChirpRequestBytes = 64 << 10
+)
+
 type Chirp struct {
 	ID        uuid.UUID `json:"id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	Body string `json:"body"`
 	UserID uuid.UUID `json:"user_id"`
+	InReplyTo uuid.NullUUID `json:"in_reply_to"`
+	RootID uuid.NullUUID `json:"root_id"`
+	LikeCount int64 `json:"like_count"`
+	LikedByMe *bool `json:"liked_by_me,omitempty"`
+	BookmarkedByMe *bool `json:"bookmarked_by_me,omitempty"`
+	RechirpOf uuid.NullUUID `json:"rechirp_of"`
+	QuoteOf uuid.NullUUID `json:"quote_of"`
+	Visibility string `json:"visibility"`
+	RechirpCount int64 `json:"rechirp_count"`
+	RechirpedChirp *Chirp `json:"rechirped_chirp,omitempty"`
+	QuotedChirp *Chirp `json:"quoted_chirp,omitempty"`
+	Mentions []MentionEntity `json:"mentions"`
+	Attachments []Attachment `json:"attachments"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+	Poll *Poll `json:"poll,omitempty"`
+	Pinned bool `json:"pinned,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type ChirpPage struct {
+	Chirps []Chirp `json:"chirps"`
+	NextCursor *string `json:"next_cursor"`
 }
 
 func (cfg *apiConfig) handlerCreateChirp(w http.ResponseWriter, req *http.Request) {
 	type parameters struct {
 		Body string `json:"body"`
+		InReplyTo uuid.NullUUID `json:"in_reply_to"`
+		RechirpOf uuid.NullUUID `json:"rechirp_of"`
+		QuoteOf uuid.NullUUID `json:"quote_of"`
+		AttachmentIDs []uuid.UUID `json:"attachment_ids"`
+		PublishAt *time.Time `json:"publish_at"`
+		Poll *PollParameters `json:"poll"`
+		TTL *int64 `json:"ttl"`
+		ExpiresAt *time.Time `json:"expires_at"`
+		Visibility string `json:"visibility"`
 	}
 
 	token, err := auth.GetBearerToken(req.Header)
@@ -37,56 +82,521 @@ func (cfg *apiConfig) handlerCreateChirp(w http.ResponseWriter, req *http.Reques
 		return
 	}
 
+	req.Body = http.MaxBytesReader(w, req.Body, maxChirpRequestBytes)
 	decoder := json.NewDecoder(req.Body)
 	data := parameters{}
 	if err := decoder.Decode(&data); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Chirp is too large", err)
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "Decoding error", err)
 		return
 	}
 
-	if ok := validateChirp(&data.Body); !ok {
-		respondWithError(w, http.StatusBadRequest, "Chirp is too long", nil)
+	limits, err := cfg.limitsFor(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting user error", err)
+		return
+	}
+
+	expiresAt := sql.NullTime{}
+	if data.RechirpOf.Valid {
+		if data.Body != "" || data.InReplyTo.Valid || data.QuoteOf.Valid || len(data.AttachmentIDs) > 0 || data.PublishAt != nil || data.Poll != nil || data.TTL != nil || data.ExpiresAt != nil || data.Visibility != "" {
+			respondWithError(w, http.StatusBadRequest, "Rechirp can't have a body, reply, quote, attachments, schedule, poll, expiry or visibility", nil)
+			return
+		}
+		original, err := cfg.getSharedChirp(req.Context(), data.RechirpOf.UUID, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusNotFound, "Rechirped chirp doesn't exist", err)
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "Getting chirp error", err)
+			return
+		}
+		// Rechirping would show a restricted chirp to the rechirper's whole audience.
+		if original.Visibility != visibilityPublic {
+			respondWithError(w, http.StatusBadRequest, "Only public chirps can be rechirped", nil)
+			return
+		}
+		data.RechirpOf.UUID = original.ID
+		// A rechirp goes away together with the chirp it shares.
+		expiresAt = original.ExpiresAt
+	}
+
+	// Masking can change the length, so the limit applies to the text as it will be stored.
+	verdict := moderation.Verdict{}
+	if !data.RechirpOf.Valid {
+		texts := []*string{&data.Body}
+		if data.Poll != nil {
+			for i := range data.Poll.Options {
+				texts = append(texts, &data.Poll.Options[i])
+			}
+		}
+		verdict = cfg.moderate(texts...)
+		if verdict.Rejected {
+			respondWithError(w, http.StatusBadRequest, "Chirp violates content rules", nil)
+			return
+		}
+		if ok := validateChirp(data.Body, limits); !ok {
+			respondWithError(w, http.StatusBadRequest, "Chirp is too long", nil)
+			return
+		}
+	}
+
+	if data.QuoteOf.Valid {
+		quoted, err := cfg.getSharedChirp(req.Context(), data.QuoteOf.UUID, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusNotFound, "Quoted chirp doesn't exist", err)
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "Getting chirp error", err)
+			return
+		}
+		data.QuoteOf.UUID = quoted.ID
+	}
+
+	attachmentIDs := []uuid.UUID{}
+	for _, attachmentID := range data.AttachmentIDs {
+		if !slices.Contains(attachmentIDs, attachmentID) {
+			attachmentIDs = append(attachmentIDs, attachmentID)
+		}
+	}
+	if len(attachmentIDs) > maxChirpAttachments {
+		respondWithError(w, http.StatusBadRequest, "Too many attachments", nil)
+		return
+	}
+
+	publishAt := sql.NullTime{}
+	if data.PublishAt != nil {
+		if !data.PublishAt.After(time.Now()) || data.PublishAt.After(time.Now().Add(maxScheduleAhead)) {
+			respondWithError(w, http.StatusBadRequest, "publish_at must be in the future and within a year", nil)
+			return
+		}
+		publishAt = sql.NullTime{Time: data.PublishAt.UTC(), Valid: true}
+	}
+
+	opensAt := time.Now()
+	if publishAt.Valid {
+		opensAt = publishAt.Time
+	}
+
+	if data.TTL != nil || data.ExpiresAt != nil {
+		expiresAt, err = parseExpiry(data.TTL, data.ExpiresAt, opensAt)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid expiry: "+err.Error(), err)
+			return
+		}
+	}
+
+	visibility, err := parseVisibility(data.Visibility)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid visibility", err)
 		return
 	}
 
-	chirp, err := cfg.database.CreateChirp(req.Context(), database.CreateChirpParams{
+	if data.Poll != nil {
+		if err := validatePoll(data.Poll, opensAt); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid poll: "+err.Error(), err)
+			return
+		}
+	}
+
+	rootID := uuid.NullUUID{}
+	if data.InReplyTo.Valid {
+		parent, err := cfg.getPublishedChirp(req.Context(), data.InReplyTo.UUID, userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				respondWithError(w, http.StatusNotFound, "Replied chirp doesn't exist", err)
+				return
+			}
+			respondWithError(w, http.StatusInternalServerError, "Getting chirp error", err)
+			return
+		}
+		rootID = parent.RootID
+		if !rootID.Valid {
+			rootID = uuid.NullUUID{UUID: parent.ID, Valid: true}
+		}
+	}
+
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Creating chrip error", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	allowed, err := chirpRateAllows(req.Context(), qtx, userID, limits)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Checking rate limit error", err)
+		return
+	}
+	if !allowed {
+		respondWithError(w, http.StatusTooManyRequests, "Too many chirps, try again later", nil)
+		return
+	}
+
+	chirp, err := qtx.CreateChirp(req.Context(), database.CreateChirpParams{
 		Body: data.Body,
 		UserID: userID,
+		ParentID: data.InReplyTo,
+		RootID: rootID,
+		RechirpOf: data.RechirpOf,
+		QuoteOf: data.QuoteOf,
+		PublishAt: publishAt,
+		ExpiresAt: expiresAt,
+		Visibility: visibility,
 	})
 	if err != nil {
+		if isUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "Chirp already rechirped", err)
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "Creating chrip error", err)
 		return
 	}
 
-	respondWithJSON(w, 201, Chirp{
+	if len(attachmentIDs) > 0 {
+		attached, err := qtx.AttachToChirp(req.Context(), database.AttachToChirpParams{
+			ChirpID: uuid.NullUUID{UUID: chirp.ID, Valid: true},
+			Ids: attachmentIDs,
+			UserID: userID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Attaching files error", err)
+			return
+		}
+		// Attachments must be the author's own uploads that aren't used by another chirp yet.
+		if attached != int64(len(attachmentIDs)) {
+			respondWithError(w, http.StatusBadRequest, "Invalid attachments", nil)
+			return
+		}
+	}
+
+	if err := flagChirp(req.Context(), qtx, chirp.ID, verdict); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Flagging chirp error", err)
+		return
+	}
+
+	if data.Poll != nil {
+		if err := savePoll(req.Context(), qtx, chirp.ID, *data.Poll); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Saving poll error", err)
+			return
+		}
+	}
+
+	// Scheduled chirps get their hashtags, mentions and webhooks when the publisher makes them visible.
+	if !chirp.PublishAt.Valid {
+		if err := saveHashtags(req.Context(), qtx, chirp); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Saving hashtags error", err)
+			return
+		}
+
+		if err := saveMentions(req.Context(), qtx, chirp); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Saving mentions error", err)
+			return
+		}
+
+		if err := enqueueWebhook(req.Context(), qtx, eventChirpCreated, webhookChirpFromDatabase(chirp)); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Queueing webhooks error", err)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Creating chrip error", err)
+		return
+	}
+
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), uuid.NullUUID{UUID: userID, Valid: true}, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Creating chrip error", err)
+		return
+	}
+
+	respondWithJSON(w, 201, chirpsJSON[0])
+}
+
+// getVisibleChirp looks up a chirp on behalf of viewerID. Chirps the viewer may not see are
+// reported as sql.ErrNoRows so that they are indistinguishable from missing ones.
+func (cfg *apiConfig) getVisibleChirp(ctx context.Context, chirpID uuid.UUID, viewerID uuid.NullUUID) (database.Chirp, error) {
+	return cfg.database.GetVisibleChirp(ctx, database.GetVisibleChirpParams{
+		ID:       chirpID,
+		ViewerID: viewerID,
+	})
+}
+
+// getPublishedChirp is getVisibleChirp for interactions, which aren't allowed on a chirp
+// that is still scheduled, not even by its author.
+func (cfg *apiConfig) getPublishedChirp(ctx context.Context, chirpID, viewerID uuid.UUID) (database.Chirp, error) {
+	chirp, err := cfg.getVisibleChirp(ctx, chirpID, uuid.NullUUID{UUID: viewerID, Valid: true})
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if chirp.PublishAt.Valid {
+		return database.Chirp{}, sql.ErrNoRows
+	}
+	return chirp, nil
+}
+
+// Sharing a rechirp shares the chirp it points to, so rechirps never nest.
+func (cfg *apiConfig) getSharedChirp(ctx context.Context, chirpID, viewerID uuid.UUID) (database.Chirp, error) {
+	chirp, err := cfg.getPublishedChirp(ctx, chirpID, viewerID)
+	if err != nil {
+		return database.Chirp{}, err
+	}
+	if chirp.RechirpOf.Valid {
+		return cfg.getPublishedChirp(ctx, chirp.RechirpOf.UUID, viewerID)
+	}
+	return chirp, nil
+}
+
+func chirpFromDatabase(chirp database.Chirp) Chirp {
+	chirpJSON := Chirp{
 		ID: chirp.ID,
 		CreatedAt: chirp.CreatedAt,
 		UpdatedAt: chirp.UpdatedAt,
 		Body: chirp.Body,
 		UserID: chirp.UserID,
+		InReplyTo: chirp.ParentID,
+		RootID: chirp.RootID,
+		RechirpOf: chirp.RechirpOf,
+		QuoteOf: chirp.QuoteOf,
+		Visibility: chirp.Visibility,
+		Mentions: []MentionEntity{},
+		Attachments: []Attachment{},
+	}
+	if chirp.PublishAt.Valid {
+		chirpJSON.PublishAt = &chirp.PublishAt.Time
+	}
+	if chirp.DeletedAt.Valid {
+		chirpJSON.DeletedAt = &chirp.DeletedAt.Time
+	}
+	if chirp.ExpiresAt.Valid {
+		chirpJSON.ExpiresAt = &chirp.ExpiresAt.Time
+	}
+	return chirpJSON
+}
+
+// chirpsToJSON renders chirps with their counters and the chirps they rechirp or quote embedded one level deep.
+func (cfg *apiConfig) chirpsToJSON(ctx context.Context, viewerID uuid.NullUUID, chirps []database.Chirp) ([]Chirp, error) {
+	chirpsJSON, err := cfg.chirpsWithCounters(ctx, viewerID, chirps)
+	if err != nil {
+		return nil, err
+	}
+
+	sharedIDs := []uuid.UUID{}
+	for _, chirp := range chirps {
+		if chirp.RechirpOf.Valid {
+			sharedIDs = append(sharedIDs, chirp.RechirpOf.UUID)
+		}
+		if chirp.QuoteOf.Valid {
+			sharedIDs = append(sharedIDs, chirp.QuoteOf.UUID)
+		}
+	}
+	if len(sharedIDs) == 0 {
+		return chirpsJSON, nil
+	}
+
+	shared, err := cfg.database.ListChirpsByIDs(ctx, database.ListChirpsByIDsParams{
+		Ids: sharedIDs,
+		ViewerID: viewerID,
 	})
+	if err != nil {
+		return nil, err
+	}
+	sharedJSON, err := cfg.chirpsWithCounters(ctx, viewerID, shared)
+	if err != nil {
+		return nil, err
+	}
+	sharedByID := map[uuid.UUID]Chirp{}
+	for _, chirp := range sharedJSON {
+		sharedByID[chirp.ID] = chirp
+	}
+
+	for i := range chirpsJSON {
+		if rechirped, ok := sharedByID[chirpsJSON[i].RechirpOf.UUID]; chirpsJSON[i].RechirpOf.Valid && ok {
+			chirpsJSON[i].RechirpedChirp = &rechirped
+		}
+		if quoted, ok := sharedByID[chirpsJSON[i].QuoteOf.UUID]; chirpsJSON[i].QuoteOf.Valid && ok {
+			chirpsJSON[i].QuotedChirp = &quoted
+		}
+	}
+
+	return chirpsJSON, nil
 }
 
-func validateChirp(body *string) bool {
-	if len(*body) > 140 {
-		return false
+// chirpsWithCounters loads the per-chirp counters in batch; viewer-specific flags are only set when viewerID is valid.
+func (cfg *apiConfig) chirpsWithCounters(ctx context.Context, viewerID uuid.NullUUID, chirps []database.Chirp) ([]Chirp, error) {
+	chirpsJSON := []Chirp{}
+	if len(chirps) == 0 {
+		return chirpsJSON, nil
+	}
+
+	chirpIDs := make([]uuid.UUID, 0, len(chirps))
+	for _, chirp := range chirps {
+		chirpIDs = append(chirpIDs, chirp.ID)
+	}
+
+	likeCounts, err := cfg.database.CountLikesByChirpIDs(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	likes := map[uuid.UUID]int64{}
+	for _, row := range likeCounts {
+		likes[row.ChirpID] = row.Count
+	}
+
+	likedByViewer := map[uuid.UUID]bool{}
+	if viewerID.Valid {
+		liked, err := cfg.database.ListLikedChirpIDs(ctx, database.ListLikedChirpIDsParams{
+			UserID: viewerID.UUID,
+			ChirpIds: chirpIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, chirpID := range liked {
+			likedByViewer[chirpID] = true
+		}
+	}
+
+	bookmarkedByViewer := map[uuid.UUID]bool{}
+	if viewerID.Valid {
+		bookmarked, err := cfg.database.ListBookmarkedChirpIDs(ctx, database.ListBookmarkedChirpIDsParams{
+			UserID: viewerID.UUID,
+			ChirpIds: chirpIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, chirpID := range bookmarked {
+			bookmarkedByViewer[chirpID] = true
+		}
 	}
 
-	replaceProfane(body)
-	return true
+	rechirpCounts, err := cfg.database.CountRechirpsByChirpIDs(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	rechirps := map[uuid.UUID]int64{}
+	for _, row := range rechirpCounts {
+		rechirps[row.RechirpOf.UUID] = row.Count
+	}
+
+	mentionRows, err := cfg.database.ListMentionsByChirpIDs(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	mentions := map[uuid.UUID][]database.Mention{}
+	for _, row := range mentionRows {
+		mentions[row.ChirpID] = append(mentions[row.ChirpID], row)
+	}
+
+	attachmentRows, err := cfg.database.ListAttachmentsByChirpIDs(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	attachments := map[uuid.UUID][]Attachment{}
+	for _, row := range attachmentRows {
+		attachments[row.ChirpID.UUID] = append(attachments[row.ChirpID.UUID], cfg.attachmentFromDatabase(row))
+	}
+
+	polls, err := cfg.pollsByChirpIDs(ctx, viewerID, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, chirp := range chirps {
+		chirpJSON := chirpFromDatabase(chirp)
+		chirpJSON.Poll = polls[chirp.ID]
+		chirpJSON.LikeCount = likes[chirp.ID]
+		if chirpAttachments, ok := attachments[chirp.ID]; ok {
+			chirpJSON.Attachments = chirpAttachments
+		}
+		for _, mention := range mentions[chirp.ID] {
+			if int(mention.EndOffset) > len(chirp.Body) {
+				continue
+			}
+			chirpJSON.Mentions = append(chirpJSON.Mentions, MentionEntity{
+				UserID: mention.UserID,
+				Handle: chirptext.NormalizeHandle(chirp.Body[mention.StartOffset:mention.EndOffset]),
+				Start: mention.StartOffset,
+				End: mention.EndOffset,
+			})
+		}
+		chirpJSON.RechirpCount = rechirps[chirp.ID]
+		if viewerID.Valid {
+			likedByMe := likedByViewer[chirp.ID]
+			chirpJSON.LikedByMe = &likedByMe
+			bookmarkedByMe := bookmarkedByViewer[chirp.ID]
+			chirpJSON.BookmarkedByMe = &bookmarkedByMe
+		}
+		chirpsJSON = append(chirpsJSON, chirpJSON)
+	}
+
+	return chirpsJSON, nil
 }
 
-func replaceProfane(s *string) {
-	splitted := strings.Split(*s, " ")
+func validateChirp(body string, limits entitlements.Limits) bool {
+	// Measuring walks every grapheme, so anything far beyond any plan's limit is turned away by size first.
+	if len(body) > maxChirpBytes {
+		return false
+	}
+	return chirptext.Measure(body, limits.MaxChirpLength).Valid()
+}
 
-	for i, item := range splitted {
-		item = strings.ToLower(item)
-		if item == "kerfuffle" || item == "sharbert" || item == "fornax"{
-			splitted[i] = "****"
+// handlerValidateChirp lets clients show the remaining budget before posting. Without a token
+// the free plan's limit applies.
+func (cfg *apiConfig) handlerValidateChirp(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Body string `json:"body"`
+	}
+	type response struct {
+		Length    int  `json:"length"`
+		Remaining int  `json:"remaining"`
+		MaxLength int  `json:"max_length"`
+		Valid     bool `json:"valid"`
+	}
+
+	limits := cfg.entitlements.Free
+	if token, err := auth.GetBearerToken(req.Header); err == nil {
+		userID, err := auth.ValidateJWT(token, cfg.secret)
+		if err != nil {
+			respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+			return
+		}
+		limits, err = cfg.limitsFor(req.Context(), userID)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Getting user error", err)
+			return
 		}
 	}
 
-	*s = strings.Join(splitted, " ")
+	data := parameters{}
+	req.Body = http.MaxBytesReader(w, req.Body, maxChirpRequestBytes)
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&data); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Chirp is too large", err)
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Decoding error", err)
+		return
+	}
+
+	measurement := chirptext.Measure(data.Body, limits.MaxChirpLength)
+	respondWithJSON(w, http.StatusOK, response{
+		Length:    measurement.Length,
+		Remaining: measurement.Remaining,
+		MaxLength: limits.MaxChirpLength,
+		Valid:     measurement.Valid(),
+	})
 }
 
 func (cfg *apiConfig) handlerGetChirps(w http.ResponseWriter, req *http.Request) {
@@ -95,47 +605,94 @@ func (cfg *apiConfig) handlerGetChirps(w http.ResponseWriter, req *http.Request)
 	if sortOrder == "" || sortOrder != "desc" {
 		sortOrder = "asc"
 	}
+
+	viewerID, err := cfg.optionalUserID(req)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	pageSize, err := parsePageSize(req.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := decodeCursor(req.URL.Query().Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	params := database.ListChirpsAscParams{
+		ViewerID: viewerID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID: cursorID,
+		PageSize: pageSize + 1,
+	}
+	params.UserID, err = parseAuthorFilter(author)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Parsing error", err)
+		return
+	}
+
 	var chirps []database.Chirp
-	var err error
-	if author == "" {
-		chirps, err = cfg.database.GetAllChirps(req.Context())
-		if err != nil {
-			respondWithError(w, http.StatusInternalServerError, "Getting chrips error", err)
-			return
-		}
+	if sortOrder == "desc" {
+		chirps, err = cfg.database.ListChirpsDesc(req.Context(), database.ListChirpsDescParams(params))
 	} else {
-		userID, err := uuid.Parse(author)
+		chirps, err = cfg.database.ListChirpsAsc(req.Context(), params)
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting chrips error", err)
+		return
+	}
+
+	chirps, nextCursor := nextPage(chirps, pageSize, func(chirp database.Chirp) (time.Time, uuid.UUID) {
+		return chirp.CreatedAt, chirp.ID
+	})
+
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), viewerID, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting chrips error", err)
+		return
+	}
+
+	// An author's pinned chirps are left out of the chronological pages and lead the first one instead.
+	if params.UserID.Valid && !cursorCreatedAt.Valid {
+		pinned, err := cfg.database.ListPinnedChirps(req.Context(), database.ListPinnedChirpsParams{
+			UserID: params.UserID.UUID,
+			ViewerID: viewerID,
+		})
 		if err != nil {
-			respondWithError(w, http.StatusInternalServerError, "Parsing error", err)
+			respondWithError(w, http.StatusInternalServerError, "Getting pinned chirps error", err)
 			return
 		}
-		chirps, err = cfg.database.GetAllChirpsByUserID(req.Context(), userID)
+		pinnedJSON, err := cfg.pinnedChirpsToJSON(req.Context(), viewerID, pinned)
 		if err != nil {
-			respondWithError(w, http.StatusInternalServerError, "Getting chrips error", err)
+			respondWithError(w, http.StatusInternalServerError, "Getting pinned chirps error", err)
 			return
 		}
+		chirpsJSON = append(pinnedJSON, chirpsJSON...)
 	}
 
-	sort.Slice(chirps, func(i, j int) bool {
-		if sortOrder == "desc" {
-			return chirps[i].CreatedAt.After(chirps[j].CreatedAt)
-		}
-		return chirps[i].CreatedAt.Before(chirps[j].CreatedAt)
+	respondWithJSON(w, 200, ChirpPage{
+		Chirps: chirpsJSON,
+		NextCursor: nextCursor,
 	})
+}
 
-	chirpsJSON := []Chirp{}
-	
-	for _, chirp := range chirps {
-		chirpsJSON = append(chirpsJSON, Chirp{
-			ID: chirp.ID,
-			CreatedAt: chirp.CreatedAt,
-			UpdatedAt: chirp.UpdatedAt,
-			Body: chirp.Body,
-			UserID: chirp.UserID,
-		})
+
+func parseAuthorFilter(author string) (uuid.NullUUID, error) {
+	if author == "" {
+		return uuid.NullUUID{}, nil
+	}
+
+	userID, err := uuid.Parse(author)
+	if err != nil {
+		return uuid.NullUUID{}, err
 	}
 
-	respondWithJSON(w, 200, chirpsJSON)
+	return uuid.NullUUID{UUID: userID, Valid: true}, nil
 }
 
 
@@ -146,7 +703,13 @@ func (cfg *apiConfig) handlerGetChirp(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	chirp, err := cfg.database.GetChirp(req.Context(), chirpID)
+	viewerID, err := cfg.optionalUserID(req)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirp, err := cfg.getVisibleChirp(req.Context(), chirpID, viewerID)
 	if err != nil {
 		if strings.Contains(err.Error(), "no rows in result set") {
 			respondWithError(w, 404, "No chirp error", err)
@@ -156,15 +719,172 @@ func (cfg *apiConfig) handlerGetChirp(w http.ResponseWriter, req *http.Request)
 		return
 	}
 
-	respondWithJSON(w, 200, Chirp{
-		ID: chirp.ID,
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), viewerID, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting chirps error", err)
+		return
+	}
+
+	respondWithJSON(w, 200, chirpsJSON[0])
+}
+
+
+func (cfg *apiConfig) handlerUpdateChirp(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Body string `json:"body"`
+	}
+
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Parsing chirpID error", err)
+		return
+	}
+
+	data := parameters{}
+	req.Body = http.MaxBytesReader(w, req.Body, maxChirpRequestBytes)
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&data); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Chirp is too large", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Decoding error", err)
+		return
+	}
+
+	chirp, err := cfg.getVisibleChirp(req.Context(), chirpID, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Getting chirp error", err)
+		return
+	}
+
+	if chirp.UserID != userID {
+		respondWithError(w, 403, "You are not author of the chirp", err)
+		return
+	}
+
+	if chirp.RechirpOf.Valid {
+		respondWithError(w, http.StatusBadRequest, "Rechirps can't be edited", nil)
+		return
+	}
+
+	limits, err := cfg.limitsFor(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting user error", err)
+		return
+	}
+	if !limits.CanEdit {
+		respondWithError(w, 403, "Your plan doesn't include editing", nil)
+		return
+	}
+	if !limits.CanEditChirp(chirp.CreatedAt, time.Now()) {
+		respondWithError(w, 403, "Edit window has passed", nil)
+		return
+	}
+
+	verdict := cfg.moderate(&data.Body)
+	if verdict.Rejected {
+		respondWithError(w, http.StatusBadRequest, "Chirp violates content rules", nil)
+		return
+	}
+
+	if ok := validateChirp(data.Body, limits); !ok {
+		respondWithError(w, http.StatusBadRequest, "Chirp is too long", nil)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Updating chirp error", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	// A concurrent edit may have landed since the read above; the revision must hold the body it replaced.
+	chirp, err = qtx.GetChirpForUpdate(req.Context(), chirp.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No chirp error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Updating chirp error", err)
+		return
+	}
+
+	if err := qtx.CreateChirpRevision(req.Context(), database.CreateChirpRevisionParams{
+		ChirpID: chirp.ID,
 		Body: chirp.Body,
-		UserID: chirp.UserID,
+	}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Saving revision error", err)
+		return
+	}
+
+	oldTags := chirptext.ExtractHashtags(chirp.Body)
+	chirp, err = qtx.UpdateChirpBody(req.Context(), database.UpdateChirpBodyParams{
+		Body: data.Body,
+		ID: chirp.ID,
 	})
-}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Updating chirp error", err)
+		return
+	}
+
+	if err := flagChirp(req.Context(), qtx, chirp.ID, verdict); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Flagging chirp error", err)
+		return
+	}
+
+	if !chirp.PublishAt.Valid {
+		if err := qtx.DeleteChirpHashtags(req.Context(), chirp.ID); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Saving hashtags error", err)
+			return
+		}
+		if err := saveHashtags(req.Context(), qtx, chirp); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Saving hashtags error", err)
+			return
+		}
+		if err := qtx.DeleteUnusedHashtags(req.Context(), oldTags); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Saving hashtags error", err)
+			return
+		}
+
+		if err := qtx.DeleteChirpMentions(req.Context(), chirp.ID); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Saving mentions error", err)
+			return
+		}
+		if err := saveMentions(req.Context(), qtx, chirp); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Saving mentions error", err)
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Updating chirp error", err)
+		return
+	}
+
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), uuid.NullUUID{UUID: userID, Valid: true}, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Updating chirp error", err)
+		return
+	}
 
+	respondWithJSON(w, http.StatusOK, chirpsJSON[0])
+}
 
 func (cfg *apiConfig) handlerDelete(w http.ResponseWriter, req *http.Request) {
 	token, err := auth.GetBearerToken(req.Header)
@@ -185,7 +905,7 @@ func (cfg *apiConfig) handlerDelete(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	chirp, err := cfg.database.GetChirp(req.Context(), chirpID)
+	chirp, err := cfg.getVisibleChirp(req.Context(), chirpID, uuid.NullUUID{UUID: userID, Valid: true})
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, "Getting chirp error", err)
 		return
@@ -196,10 +916,78 @@ func (cfg *apiConfig) handlerDelete(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	if err := cfg.database.DeleteChirp(req.Context(), chirp.ID); err != nil {
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Deleting chirp error", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	// A rechirp has nothing worth recovering, so it's removed right away instead of going to the trash.
+	// Rechirps can't have attachments, so there are no blobs to clean up afterwards.
+	if chirp.RechirpOf.Valid {
+		_, err = purgeChirps(req.Context(), qtx, []database.Chirp{chirp})
+	} else {
+		err = qtx.SoftDeleteChirp(req.Context(), chirp.ID)
+		if err == nil {
+			err = enqueueChirpDeleted(req.Context(), qtx, chirp)
+		}
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Deleting chirp error", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Deleting chirp error", err)
 		return
 	}
 
 	w.WriteHeader(204)
-}
\ No newline at end of file
+}
+
+// purgeChirps hard-deletes the chirps with their now unused hashtags. It returns the attachments
+// whose blobs the caller should remove once the transaction is committed.
+func purgeChirps(ctx context.Context, qtx *database.Queries, chirps []database.Chirp) ([]database.Attachment, error) {
+	chirpIDs := make([]uuid.UUID, 0, len(chirps))
+	tags := []string{}
+	for _, chirp := range chirps {
+		chirpIDs = append(chirpIDs, chirp.ID)
+		tags = append(tags, chirptext.ExtractHashtags(chirp.Body)...)
+	}
+
+	attachments, err := qtx.ListAttachmentsByChirpIDs(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rechirps go with their original through the foreign key, so they are announced here too.
+	rechirps, err := qtx.ListRechirpsOf(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, chirp := range rechirps {
+		if slices.Contains(chirpIDs, chirp.ID) {
+			continue
+		}
+		if err := enqueueChirpDeleted(ctx, qtx, chirp); err != nil {
+			return nil, err
+		}
+	}
+	for _, chirp := range chirps {
+		if err := enqueueChirpDeleted(ctx, qtx, chirp); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := qtx.DeleteChirpsByIDs(ctx, chirpIDs); err != nil {
+		return nil, err
+	}
+
+	if err := qtx.DeleteUnusedHashtags(ctx, tags); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
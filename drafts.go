@@ -0,0 +1,385 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/auth"
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type Draft struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Body      string    `json:"body"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
+type DraftPage struct {
+	Drafts     []Draft `json:"drafts"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+func draftFromDatabase(draft database.Draft) Draft {
+	return Draft{
+		ID:        draft.ID,
+		CreatedAt: draft.CreatedAt,
+		UpdatedAt: draft.UpdatedAt,
+		Body:      draft.Body,
+		UserID:    draft.UserID,
+	}
+}
+
+func (cfg *apiConfig) handlerCreateDraft(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Body string `json:"body"`
+	}
+
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	data := parameters{}
+	req.Body = http.MaxBytesReader(w, req.Body, maxChirpRequestBytes)
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&data); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Chirp is too large", err)
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Decoding error", err)
+		return
+	}
+
+	// Drafts may run long while they're being written; the length limit applies on publish.
+	if verdict := cfg.moderate(&data.Body); verdict.Rejected {
+		respondWithError(w, http.StatusBadRequest, "Draft violates content rules", nil)
+		return
+	}
+
+	draft, err := cfg.database.CreateDraft(req.Context(), database.CreateDraftParams{
+		UserID: userID,
+		Body:   data.Body,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Creating draft error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, draftFromDatabase(draft))
+}
+
+func (cfg *apiConfig) handlerGetDrafts(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	pageSize, err := parsePageSize(req.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := decodeCursor(req.URL.Query().Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	drafts, err := cfg.database.ListDrafts(req.Context(), database.ListDraftsParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        pageSize + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting drafts error", err)
+		return
+	}
+
+	drafts, nextCursor := nextPage(drafts, pageSize, func(draft database.Draft) (time.Time, uuid.UUID) {
+		return draft.CreatedAt, draft.ID
+	})
+
+	draftsJSON := []Draft{}
+	for _, draft := range drafts {
+		draftsJSON = append(draftsJSON, draftFromDatabase(draft))
+	}
+
+	respondWithJSON(w, http.StatusOK, DraftPage{
+		Drafts:     draftsJSON,
+		NextCursor: nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerGetDraft(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	draftID, err := uuid.Parse(req.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing draftID error", err)
+		return
+	}
+
+	// Other users' drafts are reported as missing rather than forbidden.
+	draft, err := cfg.database.GetDraft(req.Context(), database.GetDraftParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No draft error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Getting draft error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, draftFromDatabase(draft))
+}
+
+func (cfg *apiConfig) handlerUpdateDraft(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Body string `json:"body"`
+	}
+
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	draftID, err := uuid.Parse(req.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing draftID error", err)
+		return
+	}
+
+	data := parameters{}
+	req.Body = http.MaxBytesReader(w, req.Body, maxChirpRequestBytes)
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&data); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Chirp is too large", err)
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "Decoding error", err)
+		return
+	}
+
+	if verdict := cfg.moderate(&data.Body); verdict.Rejected {
+		respondWithError(w, http.StatusBadRequest, "Draft violates content rules", nil)
+		return
+	}
+
+	draft, err := cfg.database.UpdateDraft(req.Context(), database.UpdateDraftParams{
+		Body:   data.Body,
+		ID:     draftID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No draft error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Updating draft error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, draftFromDatabase(draft))
+}
+
+func (cfg *apiConfig) handlerDeleteDraft(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	draftID, err := uuid.Parse(req.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing draftID error", err)
+		return
+	}
+
+	deleted, err := cfg.database.DeleteDraft(req.Context(), database.DeleteDraftParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Deleting draft error", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "No draft error", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerPublishDraft(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	draftID, err := uuid.Parse(req.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing draftID error", err)
+		return
+	}
+
+	limits, err := cfg.limitsFor(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting user error", err)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Publishing draft error", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	// The row lock makes a concurrent publish of the same draft wait and then find it gone.
+	draft, err := qtx.GetDraftForUpdate(req.Context(), database.GetDraftForUpdateParams{
+		ID:     draftID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No draft error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Getting draft error", err)
+		return
+	}
+
+	allowed, err := chirpRateAllows(req.Context(), qtx, userID, limits)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Checking rate limit error", err)
+		return
+	}
+	if !allowed {
+		respondWithError(w, http.StatusTooManyRequests, "Too many chirps, try again later", nil)
+		return
+	}
+
+	// The rules may have changed since the draft was saved.
+	verdict := cfg.moderate(&draft.Body)
+	if verdict.Rejected {
+		respondWithError(w, http.StatusBadRequest, "Chirp violates content rules", nil)
+		return
+	}
+
+	if ok := validateChirp(draft.Body, limits); !ok {
+		respondWithError(w, http.StatusBadRequest, "Chirp is too long", nil)
+		return
+	}
+
+	chirp, err := qtx.CreateChirp(req.Context(), database.CreateChirpParams{
+		Body:       draft.Body,
+		UserID:     userID,
+		Visibility: visibilityPublic,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Creating chrip error", err)
+		return
+	}
+
+	if err := flagChirp(req.Context(), qtx, chirp.ID, verdict); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Flagging chirp error", err)
+		return
+	}
+
+	if err := saveHashtags(req.Context(), qtx, chirp); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Saving hashtags error", err)
+		return
+	}
+
+	if err := saveMentions(req.Context(), qtx, chirp); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Saving mentions error", err)
+		return
+	}
+
+	if err := enqueueWebhook(req.Context(), qtx, eventChirpCreated, webhookChirpFromDatabase(chirp)); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Queueing webhooks error", err)
+		return
+	}
+
+	if _, err := qtx.DeleteDraft(req.Context(), database.DeleteDraftParams{
+		ID:     draft.ID,
+		UserID: userID,
+	}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Deleting draft error", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Publishing draft error", err)
+		return
+	}
+
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), uuid.NullUUID{UUID: userID, Valid: true}, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Publishing draft error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, chirpsJSON[0])
+}
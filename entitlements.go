@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/Mielecki/Chirpy/internal/entitlements"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) limitsFor(ctx context.Context, userID uuid.UUID) (entitlements.Limits, error) {
+	user, err := cfg.database.GetUserByID(ctx, userID)
+	if err != nil {
+		return entitlements.Limits{}, err
+	}
+	return cfg.entitlements.For(user.IsChirpyRed.Bool), nil
+}
+
+// chirpRateAllows reports whether the user may create another chirp. Trashed and expired chirps
+// still count, so deleting doesn't free up the limit. It locks the user row, so it has to run in
+// the transaction that creates the chirp: concurrent creates then wait and count each other.
+func chirpRateAllows(ctx context.Context, qtx *database.Queries, userID uuid.UUID, limits entitlements.Limits) (bool, error) {
+	if limits.ChirpsPerHour == 0 {
+		return true, nil
+	}
+
+	if _, err := qtx.GetUserByIDForUpdate(ctx, userID); err != nil {
+		return false, err
+	}
+
+	count, err := qtx.CountChirpsSince(ctx, database.CountChirpsSinceParams{
+		UserID: userID,
+		Since:  time.Now().Add(-time.Hour).UTC(),
+	})
+	if err != nil {
+		return false, err
+	}
+	return count < int64(limits.ChirpsPerHour), nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/database"
+)
+
+const (
+	minChirpLifetime = time.Minute
+	maxChirpLifetime = 30 * 24 * time.Hour
+	sweeperInterval  = time.Minute
+)
+
+// parseExpiry accepts either a ttl in seconds or an absolute expires_at; both count from when the chirp opens.
+func parseExpiry(ttl *int64, expiresAt *time.Time, opensAt time.Time) (sql.NullTime, error) {
+	if ttl != nil && expiresAt != nil {
+		return sql.NullTime{}, errors.New("set either ttl or expires_at, not both")
+	}
+
+	expiry := time.Time{}
+	if ttl != nil {
+		// Checked before converting so a huge ttl can't overflow into the allowed range.
+		if *ttl < 0 || *ttl > int64(maxChirpLifetime/time.Second) {
+			return sql.NullTime{}, errors.New("chirp must live for 1 minute to 30 days")
+		}
+		expiry = opensAt.Add(time.Duration(*ttl) * time.Second)
+	} else {
+		expiry = *expiresAt
+	}
+
+	lifetime := expiry.Sub(opensAt)
+	if lifetime < minChirpLifetime || lifetime > maxChirpLifetime {
+		return sql.NullTime{}, errors.New("chirp must live for 1 minute to 30 days")
+	}
+
+	return sql.NullTime{Time: expiry.UTC(), Valid: true}, nil
+}
+
+// Read queries already hide expired chirps; the sweeper only reclaims their rows and blobs.
+func (cfg *apiConfig) sweepExpiredChirps(ctx context.Context) error {
+	return cfg.purgeInBatches(ctx, func(ctx context.Context, qtx *database.Queries) ([]database.Chirp, error) {
+		return qtx.ListExpiredChirps(ctx, purgeBatchSize)
+	})
+}
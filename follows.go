@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/auth"
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type UserPage struct {
+	Users      []User  `json:"users"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+func (cfg *apiConfig) handlerFollow(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	followerID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	followeeID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing userID error", err)
+		return
+	}
+
+	if followeeID == followerID {
+		respondWithError(w, http.StatusBadRequest, "You can't follow yourself", nil)
+		return
+	}
+
+	if _, err := cfg.database.GetUserByID(req.Context(), followeeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No user error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Getting user error", err)
+		return
+	}
+
+	if err := cfg.database.CreateFollow(req.Context(), database.CreateFollowParams{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Following error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnfollow(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	followerID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	followeeID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing userID error", err)
+		return
+	}
+
+	if err := cfg.database.DeleteFollow(req.Context(), database.DeleteFollowParams{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unfollowing error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerGetFollowers(w http.ResponseWriter, req *http.Request) {
+	cfg.respondWithFollowList(w, req, cfg.database.ListFollowers)
+}
+
+func (cfg *apiConfig) handlerGetFollowing(w http.ResponseWriter, req *http.Request) {
+	cfg.respondWithFollowList(w, req, func(ctx context.Context, arg database.ListFollowersParams) ([]database.ListFollowersRow, error) {
+		rows, err := cfg.database.ListFollowing(ctx, database.ListFollowingParams(arg))
+		if err != nil {
+			return nil, err
+		}
+
+		users := make([]database.ListFollowersRow, 0, len(rows))
+		for _, row := range rows {
+			users = append(users, database.ListFollowersRow(row))
+		}
+		return users, nil
+	})
+}
+
+func (cfg *apiConfig) respondWithFollowList(w http.ResponseWriter, req *http.Request, list func(context.Context, database.ListFollowersParams) ([]database.ListFollowersRow, error)) {
+	userID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing userID error", err)
+		return
+	}
+
+	pageSize, err := parsePageSize(req.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := decodeCursor(req.URL.Query().Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	rows, err := list(req.Context(), database.ListFollowersParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        pageSize + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting users error", err)
+		return
+	}
+
+	rows, nextCursor := nextPage(rows, pageSize, func(row database.ListFollowersRow) (time.Time, uuid.UUID) {
+		return row.FollowedAt, row.ID
+	})
+
+	// Listings are public, so they leave out the email address.
+	users := []User{}
+	for _, row := range rows {
+		users = append(users, User{
+			ID:          row.ID,
+			CreatedAt:   row.CreatedAt,
+			UpdatedAt:   row.UpdatedAt,
+			IsChirpyRed: row.IsChirpyRed.Bool,
+			Handle:      row.Handle.String,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, UserPage{
+		Users:      users,
+		NextCursor: nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerTimeline(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	pageSize, err := parsePageSize(req.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := decodeCursor(req.URL.Query().Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	chirps, err := cfg.database.ListTimelineChirps(req.Context(), database.ListTimelineChirpsParams{
+		FollowerID:      userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        pageSize + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting timeline error", err)
+		return
+	}
+
+	chirps, nextCursor := nextPage(chirps, pageSize, func(chirp database.Chirp) (time.Time, uuid.UUID) {
+		return chirp.CreatedAt, chirp.ID
+	})
+
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), uuid.NullUUID{UUID: userID, Valid: true}, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting timeline error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ChirpPage{
+		Chirps:     chirpsJSON,
+		NextCursor: nextCursor,
+	})
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/chirptext"
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTrendingWindow = 24 * time.Hour
+	maxTrendingWindow     = 7 * 24 * time.Hour
+)
+
+type TrendingHashtag struct {
+	Tag        string `json:"tag"`
+	ChirpCount int64  `json:"chirp_count"`
+}
+
+func saveHashtags(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	tags := chirptext.ExtractHashtags(chirp.Body)
+	if len(tags) == 0 {
+		return nil
+	}
+
+	if err := q.CreateHashtags(ctx, tags); err != nil {
+		return err
+	}
+
+	return q.CreateChirpHashtags(ctx, database.CreateChirpHashtagsParams{
+		ChirpID: chirp.ID,
+		Tags:    tags,
+	})
+}
+
+func (cfg *apiConfig) handlerGetHashtagChirps(w http.ResponseWriter, req *http.Request) {
+	tag := chirptext.NormalizeHashtag(req.PathValue("tag"))
+
+	viewerID, err := cfg.optionalUserID(req)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	pageSize, err := parsePageSize(req.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := decodeCursor(req.URL.Query().Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	chirps, err := cfg.database.ListHashtagChirps(req.Context(), database.ListHashtagChirpsParams{
+		Tag:             tag,
+		ViewerID:        viewerID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        pageSize + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting chirps error", err)
+		return
+	}
+
+	chirps, nextCursor := nextPage(chirps, pageSize, func(chirp database.Chirp) (time.Time, uuid.UUID) {
+		return chirp.CreatedAt, chirp.ID
+	})
+
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), viewerID, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting chirps error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ChirpPage{
+		Chirps:     chirpsJSON,
+		NextCursor: nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerGetTrendingHashtags(w http.ResponseWriter, req *http.Request) {
+	window := defaultTrendingWindow
+	if param := req.URL.Query().Get("window"); param != "" {
+		parsed, err := time.ParseDuration(param)
+		if err != nil || parsed <= 0 || parsed > maxTrendingWindow {
+			respondWithError(w, http.StatusBadRequest, "Invalid window", err)
+			return
+		}
+		window = parsed
+	}
+
+	pageSize, err := parsePageSize(req.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	rows, err := cfg.database.ListTrendingHashtags(req.Context(), database.ListTrendingHashtagsParams{
+		WindowSeconds: window.Seconds(),
+		PageSize:      pageSize,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting trending hashtags error", err)
+		return
+	}
+
+	trending := []TrendingHashtag{}
+	for _, row := range rows {
+		trending = append(trending, TrendingHashtag{
+			Tag:        row.Tag,
+			ChirpCount: row.ChirpCount,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, trending)
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func signedHeaders(timestamp time.Time, body []byte, secrets ...string) http.Header {
+	headers := http.Header{}
+	headers.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	signature := ""
+	for i, secret := range secrets {
+		if i > 0 {
+			signature += ", "
+		}
+		signature += "v1=" + SignWebhook(secret, timestamp.Unix(), body)
+	}
+	headers.Set(WebhookSignatureHeader, signature)
+	return headers
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"event":"user.upgraded"}`)
+	tolerance := 5 * time.Minute
+
+	tests := []struct {
+		name    string
+		headers http.Header
+		body    []byte
+		secrets []string
+		want    error
+	}{
+		{name: "valid", headers: signedHeaders(now, body, "new"), body: body, secrets: []string{"new"}},
+		{name: "old secret during rotation", headers: signedHeaders(now, body, "old"), body: body, secrets: []string{"new", "old"}},
+		{name: "signed with both", headers: signedHeaders(now, body, "retired", "new"), body: body, secrets: []string{"new"}},
+		{name: "wrong secret", headers: signedHeaders(now, body, "guess"), body: body, secrets: []string{"new"}, want: ErrWebhookSignature},
+		{name: "tampered body", headers: signedHeaders(now, body, "new"), body: []byte(`{"event":"user.downgraded"}`), secrets: []string{"new"}, want: ErrWebhookSignature},
+		{name: "too old", headers: signedHeaders(now.Add(-tolerance-time.Second), body, "new"), body: body, secrets: []string{"new"}, want: ErrWebhookTimestamp},
+		{name: "from the future", headers: signedHeaders(now.Add(tolerance+time.Second), body, "new"), body: body, secrets: []string{"new"}, want: ErrWebhookTimestamp},
+		{name: "no secrets", headers: signedHeaders(now, body, "new"), body: body, want: ErrWebhookSignature},
+	}
+
+	for _, test := range tests {
+		if err := VerifyWebhook(test.headers, test.body, test.secrets, tolerance, now); !errors.Is(err, test.want) {
+			t.Fatalf("%s: VerifyWebhook = %v, want %v", test.name, err, test.want)
+		}
+	}
+
+	headers := signedHeaders(now, body, "new")
+	headers.Set(WebhookTimestampHeader, strconv.FormatInt(now.Unix()+1, 10))
+	if err := VerifyWebhook(headers, body, []string{"new"}, tolerance, now); !errors.Is(err, ErrWebhookSignature) {
+		t.Fatalf("VerifyWebhook with a changed timestamp = %v, want %v", err, ErrWebhookSignature)
+	}
+
+	if err := VerifyWebhook(http.Header{}, body, []string{"new"}, tolerance, now); err == nil {
+		t.Fatalf("VerifyWebhook without headers succeeded")
+	}
+}
+
+func TestKeysMatch(t *testing.T) {
+	if !KeysMatch("secret", "secret") || KeysMatch("secret", "secreT") || KeysMatch("", "") {
+		t.Fatalf("KeysMatch compared keys wrongly")
+	}
+}
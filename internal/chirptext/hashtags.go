@@ -0,0 +1,62 @@
+package chirptext
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const maxHashtagLength = 100
+
+// ExtractHashtags returns the distinct, lowercased tags of every #hashtag in
+// body, in order of first appearance. A hashtag starts at a # that is not
+// glued to a preceding word, consists of letters, digits and underscores and
+// must contain at least one letter, so "#1" or "a#b" are not tags.
+func ExtractHashtags(body string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+
+	for i := 0; i < len(body); i++ {
+		if body[i] != '#' || !isBoundary(body, i) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(body) {
+			r, size := utf8.DecodeRuneInString(body[end:])
+			if !isTagRune(r) {
+				break
+			}
+			end += size
+		}
+
+		tag := strings.ToLower(body[i+1 : end])
+		if tag == "" || utf8.RuneCountInString(tag) > maxHashtagLength || !strings.ContainsFunc(tag, unicode.IsLetter) {
+			continue
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+		i = end - 1
+	}
+
+	return tags
+}
+
+// NormalizeHashtag turns user input such as "#Go" into the stored form of the tag.
+func NormalizeHashtag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(tag, "#"))
+}
+
+func isBoundary(body string, i int) bool {
+	if i == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(body[:i])
+	return !isTagRune(r) && r != '#'
+}
+
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r) || r == '_'
+}
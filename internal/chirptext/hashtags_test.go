@@ -0,0 +1,26 @@
+package chirptext
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractHashtags(t *testing.T) {
+	tests := []struct {
+		body string
+		want []string
+	}{
+		{body: "no tags here", want: []string{}},
+		{body: "#Go and #go again", want: []string{"go"}},
+		{body: "Loving #golang, #sql!", want: []string{"golang", "sql"}},
+		{body: "#1 is not a tag but #web3 is", want: []string{"web3"}},
+		{body: "email me at a#b or ##double", want: []string{}},
+		{body: "#zażółć #snake_case", want: []string{"zażółć", "snake_case"}},
+	}
+
+	for _, test := range tests {
+		if got := ExtractHashtags(test.body); !slices.Equal(got, test.want) {
+			t.Fatalf("ExtractHashtags(%q) = %q, want %q", test.body, got, test.want)
+		}
+	}
+}
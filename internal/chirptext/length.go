@@ -0,0 +1,224 @@
+package chirptext
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// URLLength is what every link counts as, however long it really is.
+const URLLength = 23
+
+const zwj = '\u200d'
+
+// Measurement is how much of the length budget a chirp body uses.
+type Measurement struct {
+	Length    int
+	Remaining int
+}
+
+// Valid reports whether the body fits in the limit it was measured against.
+func (m Measurement) Valid() bool {
+	return m.Remaining >= 0
+}
+
+// Measure counts body in user-perceived characters, with every http(s) link
+// counted as URLLength, against a limit of maxLength.
+func Measure(body string, maxLength int) Measurement {
+	length := 0
+	for {
+		start, end := findURL(body)
+		if start < 0 {
+			length += CountGraphemes(body)
+			break
+		}
+		length += CountGraphemes(body[:start]) + URLLength
+		body = body[end:]
+	}
+
+	return Measurement{
+		Length:    length,
+		Remaining: maxLength - length,
+	}
+}
+
+// findURL returns the byte span of the first link in body, or -1, -1. A link
+// runs from http:// or https:// to the next space, minus trailing punctuation
+// that most likely belongs to the sentence.
+func findURL(body string) (int, int) {
+	offset := 0
+	for {
+		i := strings.Index(body[offset:], "http")
+		if i < 0 {
+			return -1, -1
+		}
+		start := offset + i
+		offset = start + len("http")
+
+		rest := body[start:]
+		scheme := ""
+		if strings.HasPrefix(rest, "https://") {
+			scheme = "https://"
+		} else if strings.HasPrefix(rest, "http://") {
+			scheme = "http://"
+		} else {
+			continue
+		}
+		if start > 0 {
+			r, _ := utf8.DecodeLastRuneInString(body[:start])
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				continue
+			}
+		}
+
+		end := start + len(scheme)
+		for end < len(body) {
+			r, size := utf8.DecodeRuneInString(body[end:])
+			if unicode.IsSpace(r) {
+				break
+			}
+			end += size
+		}
+		for end > start+len(scheme) {
+			last := body[end-1]
+			if strings.IndexByte(".,:;!?'\"", last) >= 0 || (last == ')' && !strings.Contains(body[start:end-1], "(")) {
+				end--
+				continue
+			}
+			break
+		}
+		if end == start+len(scheme) {
+			continue
+		}
+		return start, end
+	}
+}
+
+// CountGraphemes returns the number of user-perceived characters in s. It
+// follows the extended grapheme cluster rules of UAX #29 closely enough for
+// chirps: combining marks, emoji with modifiers, ZWJ sequences, flags and
+// Hangul syllables each count once.
+func CountGraphemes(s string) int {
+	count := 0
+	prev := rune(-1)
+	regional := 0            // regional indicators in a row, up to and including prev
+	pictographic := false    // the cluster is a pictograph followed only by extenders
+	pictographicZWJ := false // ... and then a ZWJ, so a pictograph may join it
+
+	for _, r := range s {
+		joined := prev >= 0 && !isBreak(prev, r, regional, pictographicZWJ)
+		if !joined {
+			count++
+		}
+
+		switch {
+		case r == zwj:
+			pictographicZWJ = pictographic
+			pictographic = false
+		case isPictographic(r):
+			pictographic = true
+			pictographicZWJ = false
+		case joined && isExtend(r):
+			pictographicZWJ = false
+		default:
+			pictographic = false
+			pictographicZWJ = false
+		}
+
+		if isRegional(r) {
+			regional++
+		} else {
+			regional = 0
+		}
+		prev = r
+	}
+
+	return count
+}
+
+func isBreak(prev, r rune, regional int, pictographicZWJ bool) bool {
+	switch {
+	case prev == '\r' && r == '\n':
+		return false
+	case isControl(prev) || isControl(r):
+		return true
+	case joinsHangul(hangulType(prev), hangulType(r)):
+		return false
+	case isExtend(r) || r == zwj || unicode.Is(unicode.Mc, r):
+		return false
+	case prev == zwj && pictographicZWJ && isPictographic(r):
+		return false
+	case isRegional(prev) && isRegional(r):
+		// Regional indicators pair up into flags.
+		return regional%2 == 0
+	}
+	return true
+}
+
+func isControl(r rune) bool {
+	return unicode.In(r, unicode.Cc, unicode.Zl, unicode.Zp)
+}
+
+func isExtend(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me) ||
+		r == '\u200c' ||
+		(r >= 0x1f3fb && r <= 0x1f3ff) || // emoji skin tone modifiers
+		(r >= 0xe0020 && r <= 0xe007f) // tags, as in subdivision flags
+}
+
+func isRegional(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// isPictographic approximates Extended_Pictographic by the blocks emoji are drawn from.
+func isPictographic(r rune) bool {
+	switch {
+	case r == 0xa9 || r == 0xae || r == 0x203c || r == 0x2049 || r == 0x2122 || r == 0x2139:
+		return true
+	case r >= 0x2190 && r <= 0x21ff, r >= 0x2300 && r <= 0x23ff, r >= 0x2600 && r <= 0x27bf, r >= 0x2b00 && r <= 0x2bff:
+		return true
+	case r >= 0x1f000 && r <= 0x1faff && !isRegional(r) && !(r >= 0x1f3fb && r <= 0x1f3ff):
+		return true
+	}
+	return false
+}
+
+type hangul int
+
+const (
+	hangulNone hangul = iota
+	hangulL
+	hangulV
+	hangulT
+	hangulLV
+	hangulLVT
+)
+
+func hangulType(r rune) hangul {
+	switch {
+	case r >= 0x1100 && r <= 0x115f, r >= 0xa960 && r <= 0xa97c:
+		return hangulL
+	case r >= 0x1160 && r <= 0x11a7, r >= 0xd7b0 && r <= 0xd7c6:
+		return hangulV
+	case r >= 0x11a8 && r <= 0x11ff, r >= 0xd7cb && r <= 0xd7fb:
+		return hangulT
+	case r >= 0xac00 && r <= 0xd7a3:
+		if (r-0xac00)%28 == 0 {
+			return hangulLV
+		}
+		return hangulLVT
+	}
+	return hangulNone
+}
+
+func joinsHangul(prev, r hangul) bool {
+	switch prev {
+	case hangulL:
+		return r == hangulL || r == hangulV || r == hangulLV || r == hangulLVT
+	case hangulLV, hangulV:
+		return r == hangulV || r == hangulT
+	case hangulLVT, hangulT:
+		return r == hangulT
+	}
+	return false
+}
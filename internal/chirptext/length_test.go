@@ -0,0 +1,61 @@
+package chirptext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCountGraphemes(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{s: "", want: 0},
+		{s: "hello", want: 5},
+		{s: "zażółć gęślą jaźń", want: 17},
+		{s: "é", want: 1},
+		{s: "👍🏽", want: 1},
+		{s: "👩\u200d👩\u200d👧\u200d👦", want: 1},
+		{s: "🇵🇱🇩🇪", want: 2},
+		{s: "🇵🇱🇩", want: 2},
+		{s: "1️⃣", want: 1},
+		{s: "각", want: 1},
+		{s: "한국어", want: 3},
+		{s: "a\r\nb", want: 3},
+		{s: "a\u200db", want: 2},
+	}
+
+	for _, test := range tests {
+		if got := CountGraphemes(test.s); got != test.want {
+			t.Fatalf("CountGraphemes(%q) = %d, want %d", test.s, got, test.want)
+		}
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	tests := []struct {
+		body string
+		want int
+	}{
+		{body: "hi", want: 2},
+		{body: "see https://example.com/a/very/long/path?with=query", want: 4 + URLLength},
+		{body: "(http://go.dev).", want: 1 + URLLength + 2},
+		{body: "https://en.wikipedia.org/wiki/Go_(game)", want: URLLength},
+		{body: "http:// alone", want: 13},
+		{body: "nothttp://example.com", want: 21},
+		{body: "a https://a.io b http://b.io", want: 2 + URLLength + 3 + URLLength},
+	}
+
+	for _, test := range tests {
+		if got := Measure(test.body, 140).Length; got != test.want {
+			t.Fatalf("Measure(%q, 140).Length = %d, want %d", test.body, got, test.want)
+		}
+	}
+
+	if m := Measure(strings.Repeat("ż", 140), 140); !m.Valid() || m.Remaining != 0 {
+		t.Fatalf("Measure of 140 Polish letters = %+v, want it to fit exactly", m)
+	}
+	if m := Measure(strings.Repeat("🙂", 141), 140); m.Valid() || m.Remaining != -1 {
+		t.Fatalf("Measure of 141 emoji = %+v, want one over", m)
+	}
+}
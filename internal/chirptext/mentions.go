@@ -0,0 +1,79 @@
+package chirptext
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	minHandleLength = 3
+	maxHandleLength = 30
+)
+
+// Mention is an @handle in a chirp body. Start and End are byte offsets of
+// the whole "@handle" span, End exclusive, so body[Start:End] == "@"+Handle
+// before normalization.
+type Mention struct {
+	Handle string
+	Start  int
+	End    int
+}
+
+// ExtractMentions returns every @handle in body. Like hashtags, the @ must
+// not be glued to a preceding word, which keeps e-mail addresses out.
+func ExtractMentions(body string) []Mention {
+	mentions := []Mention{}
+
+	for i := 0; i < len(body); i++ {
+		if body[i] != '@' || !isMentionBoundary(body, i) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(body) && isHandleByte(body[end]) {
+			end++
+		}
+
+		if handle := body[i+1 : end]; ValidHandle(handle) {
+			mentions = append(mentions, Mention{
+				Handle: NormalizeHandle(handle),
+				Start:  i,
+				End:    end,
+			})
+		}
+		i = end - 1
+	}
+
+	return mentions
+}
+
+// ValidHandle reports whether handle may be used as a user's handle: 3 to 30
+// ASCII letters, digits or underscores.
+func ValidHandle(handle string) bool {
+	if len(handle) < minHandleLength || len(handle) > maxHandleLength {
+		return false
+	}
+	for i := 0; i < len(handle); i++ {
+		if !isHandleByte(handle[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Handles are unique regardless of case, so they are stored lowercased.
+func NormalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(handle, "@"))
+}
+
+func isMentionBoundary(body string, i int) bool {
+	if i == 0 {
+		return true
+	}
+	r, _ := utf8.DecodeLastRuneInString(body[:i])
+	return !isTagRune(r) && r != '@'
+}
+
+func isHandleByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' || b == '_'
+}
@@ -0,0 +1,35 @@
+package chirptext
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExtractMentions(t *testing.T) {
+	tests := []struct {
+		body string
+		want []Mention
+	}{
+		{body: "hello world", want: []Mention{}},
+		{body: "@Alice hi", want: []Mention{{Handle: "alice", Start: 0, End: 6}}},
+		{body: "cc @bob_1, @carol!", want: []Mention{{Handle: "bob_1", Start: 3, End: 9}, {Handle: "carol", Start: 11, End: 17}}},
+		{body: "mail me@example.com, żółw@ok or @@dave", want: []Mention{}},
+		{body: "@ab is too short", want: []Mention{}},
+		{body: "zażółć @eve", want: []Mention{{Handle: "eve", Start: 11, End: 15}}},
+	}
+
+	for _, test := range tests {
+		if got := ExtractMentions(test.body); !slices.Equal(got, test.want) {
+			t.Fatalf("ExtractMentions(%q) = %v, want %v", test.body, got, test.want)
+		}
+	}
+}
+
+func TestValidHandle(t *testing.T) {
+	if !ValidHandle("chirpy_fan42") {
+		t.Fatalf("ValidHandle rejected a valid handle")
+	}
+	if ValidHandle("no spaces") || ValidHandle("ab") || ValidHandle("łukasz") {
+		t.Fatalf("ValidHandle accepted an invalid handle")
+	}
+}
@@ -7,29 +7,114 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const countChirpsSince = `-- name: CountChirpsSince :one
+SELECT COUNT(*)
+FROM chirps
+WHERE user_id = $1 AND created_at > $2
+`
+
+type CountChirpsSinceParams struct {
+	UserID uuid.UUID
+	Since  time.Time
+}
+
+func (q *Queries) CountChirpsSince(ctx context.Context, arg CountChirpsSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countChirpsSince, arg.UserID, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countRechirpsByChirpIDs = `-- name: CountRechirpsByChirpIDs :many
+SELECT rechirp_of, COUNT(*)
+FROM chirps
+WHERE rechirp_of = ANY($1::uuid[])
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+GROUP BY rechirp_of
+`
+
+type CountRechirpsByChirpIDsRow struct {
+	RechirpOf uuid.NullUUID
+	Count     int64
+}
+
+func (q *Queries) CountRechirpsByChirpIDs(ctx context.Context, chirpIds []uuid.UUID) ([]CountRechirpsByChirpIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, countRechirpsByChirpIDs, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountRechirpsByChirpIDsRow
+	for rows.Next() {
+		var i CountRechirpsByChirpIDsRow
+		if err := rows.Scan(
+			&i.RechirpOf,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, publish_at, expires_at, visibility)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
 )
-RETURNING id, created_at, updated_at, body, user_id
+RETURNING id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
 `
 
 type CreateChirpParams struct {
-	Body   string
-	UserID uuid.UUID
+	Body       string
+	UserID     uuid.UUID
+	ParentID   uuid.NullUUID
+	RootID     uuid.NullUUID
+	RechirpOf  uuid.NullUUID
+	QuoteOf    uuid.NullUUID
+	PublishAt  sql.NullTime
+	ExpiresAt  sql.NullTime
+	Visibility string
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, createChirp, arg.Body, arg.UserID)
+	row := q.db.QueryRowContext(ctx, createChirp,
+		arg.Body,
+		arg.UserID,
+		arg.ParentID,
+		arg.RootID,
+		arg.RechirpOf,
+		arg.QuoteOf,
+		arg.PublishAt,
+		arg.ExpiresAt,
+		arg.Visibility,
+	)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -37,6 +122,15 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.ParentID,
+		&i.RootID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.SearchVector,
+		&i.PublishAt,
+		&i.DeletedAt,
+		&i.ExpiresAt,
+		&i.Visibility,
 	)
 	return i, err
 }
@@ -52,14 +146,318 @@ func (q *Queries) DeleteChirp(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
-const getAllChirps = `-- name: GetAllChirps :many
-SELECT id, created_at, updated_at, body, user_id
+const deleteChirpsByIDs = `-- name: DeleteChirpsByIDs :exec
+DELETE
+FROM chirps
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) DeleteChirpsByIDs(ctx context.Context, ids []uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteChirpsByIDs, pq.Array(ids))
+	return err
+}
+
+const getChirp = `-- name: GetChirp :one
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
+FROM chirps
+WHERE id = $1
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+`
+
+func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirp, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.RootID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.SearchVector,
+		&i.PublishAt,
+		&i.DeletedAt,
+		&i.ExpiresAt,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const getChirpForUpdate = `-- name: GetChirpForUpdate :one
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
+FROM chirps
+WHERE id = $1
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+FOR UPDATE
+`
+
+func (q *Queries) GetChirpForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpForUpdate, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.RootID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.SearchVector,
+		&i.PublishAt,
+		&i.DeletedAt,
+		&i.ExpiresAt,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const getVisibleChirp = `-- name: GetVisibleChirp :one
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
+FROM chirps
+WHERE id = $1
+AND (publish_at IS NULL OR user_id = $2)
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+AND chirp_visible_to(id, user_id, visibility, $2)
+`
+
+type GetVisibleChirpParams struct {
+	ID       uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) GetVisibleChirp(ctx context.Context, arg GetVisibleChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getVisibleChirp, arg.ID, arg.ViewerID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.RootID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.SearchVector,
+		&i.PublishAt,
+		&i.DeletedAt,
+		&i.ExpiresAt,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const listChirpsAsc = `-- name: ListChirpsAsc :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
+FROM chirps
+WHERE ($1::uuid IS NULL OR user_id = $1)
+AND (publish_at IS NULL OR user_id = $2)
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+AND chirp_visible_to(id, user_id, visibility, $2)
+AND ($1::uuid IS NULL OR id NOT IN (SELECT chirp_id FROM pinned_chirps WHERE pinned_chirps.user_id = $1))
+AND ($3::timestamp IS NULL OR (created_at, id) > ($3::timestamp, $4::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT $5
+`
+
+type ListChirpsAscParams struct {
+	UserID          uuid.NullUUID
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageSize        int32
+}
+
+func (q *Queries) ListChirpsAsc(ctx context.Context, arg ListChirpsAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsAsc,
+		arg.UserID,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.RootID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.SearchVector,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ExpiresAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsByIDs = `-- name: ListChirpsByIDs :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
+FROM chirps
+WHERE id = ANY($1::uuid[])
+AND publish_at IS NULL
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+AND chirp_visible_to(id, user_id, visibility, $2)
+`
+
+type ListChirpsByIDsParams struct {
+	Ids      []uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) ListChirpsByIDs(ctx context.Context, arg ListChirpsByIDsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsByIDs, pq.Array(arg.Ids), arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.RootID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.SearchVector,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ExpiresAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listChirpsDesc = `-- name: ListChirpsDesc :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
+FROM chirps
+WHERE ($1::uuid IS NULL OR user_id = $1)
+AND (publish_at IS NULL OR user_id = $2)
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+AND chirp_visible_to(id, user_id, visibility, $2)
+AND ($1::uuid IS NULL OR id NOT IN (SELECT chirp_id FROM pinned_chirps WHERE pinned_chirps.user_id = $1))
+AND ($3::timestamp IS NULL OR (created_at, id) < ($3::timestamp, $4::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT $5
+`
+
+type ListChirpsDescParams struct {
+	UserID          uuid.NullUUID
+	ViewerID        uuid.NullUUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageSize        int32
+}
+
+func (q *Queries) ListChirpsDesc(ctx context.Context, arg ListChirpsDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listChirpsDesc,
+		arg.UserID,
+		arg.ViewerID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.RootID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.SearchVector,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ExpiresAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listConversationChirps = `-- name: ListConversationChirps :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
 FROM chirps
-ORDER BY created_at
+WHERE (id = $1 OR root_id = $1)
+AND publish_at IS NULL
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+AND chirp_visible_to(id, user_id, visibility, $2)
+ORDER BY created_at, id
 `
 
-func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getAllChirps)
+type ListConversationChirpsParams struct {
+	RootID   uuid.UUID
+	ViewerID uuid.NullUUID
+}
+
+func (q *Queries) ListConversationChirps(ctx context.Context, arg ListConversationChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listConversationChirps, arg.RootID, arg.ViewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -73,6 +471,15 @@ func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.ParentID,
+			&i.RootID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.SearchVector,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ExpiresAt,
+			&i.Visibility,
 		); err != nil {
 			return nil, err
 		}
@@ -87,15 +494,200 @@ func (q *Queries) GetAllChirps(ctx context.Context) ([]Chirp, error) {
 	return items, nil
 }
 
-const getAllChirpsByUserID = `-- name: GetAllChirpsByUserID :many
-SELECT id, created_at, updated_at, body, user_id
+const listConversationLinks = `-- name: ListConversationLinks :many
+SELECT id, parent_id
+FROM chirps
+WHERE (id = $1 OR root_id = $1)
+AND publish_at IS NULL
+ORDER BY created_at, id
+`
+
+type ListConversationLinksRow struct {
+	ID       uuid.UUID
+	ParentID uuid.NullUUID
+}
+
+func (q *Queries) ListConversationLinks(ctx context.Context, rootID uuid.UUID) ([]ListConversationLinksRow, error) {
+	rows, err := q.db.QueryContext(ctx, listConversationLinks, rootID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListConversationLinksRow
+	for rows.Next() {
+		var i ListConversationLinksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ParentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listExpiredChirps = `-- name: ListExpiredChirps :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
+FROM chirps
+WHERE expires_at <= NOW()
+ORDER BY expires_at
+LIMIT $1
+FOR UPDATE SKIP LOCKED
+`
+
+func (q *Queries) ListExpiredChirps(ctx context.Context, batchSize int32) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listExpiredChirps, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.RootID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.SearchVector,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ExpiresAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPurgeableChirps = `-- name: ListPurgeableChirps :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
+FROM chirps
+WHERE deleted_at < NOW() - make_interval(secs => $1::float8)
+ORDER BY deleted_at
+LIMIT $2
+FOR UPDATE SKIP LOCKED
+`
+
+type ListPurgeableChirpsParams struct {
+	RetentionSeconds float64
+	BatchSize        int32
+}
+
+func (q *Queries) ListPurgeableChirps(ctx context.Context, arg ListPurgeableChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listPurgeableChirps, arg.RetentionSeconds, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.RootID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.SearchVector,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ExpiresAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRechirpsOf = `-- name: ListRechirpsOf :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
+FROM chirps
+WHERE rechirp_of = ANY($1::uuid[])
+`
+
+func (q *Queries) ListRechirpsOf(ctx context.Context, chirpIds []uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listRechirpsOf, pq.Array(chirpIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.RootID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.SearchVector,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ExpiresAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listScheduledChirps = `-- name: ListScheduledChirps :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
 FROM chirps
 WHERE user_id = $1
-ORDER BY created_at
+AND publish_at IS NOT NULL
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY publish_at, id
 `
 
-func (q *Queries) GetAllChirpsByUserID(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getAllChirpsByUserID, userID)
+func (q *Queries) ListScheduledChirps(ctx context.Context, userID uuid.UUID) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listScheduledChirps, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -109,6 +701,15 @@ func (q *Queries) GetAllChirpsByUserID(ctx context.Context, userID uuid.UUID) ([
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.ParentID,
+			&i.RootID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.SearchVector,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ExpiresAt,
+			&i.Visibility,
 		); err != nil {
 			return nil, err
 		}
@@ -123,14 +724,224 @@ func (q *Queries) GetAllChirpsByUserID(ctx context.Context, userID uuid.UUID) ([
 	return items, nil
 }
 
-const getChirp = `-- name: GetChirp :one
-SELECT id, created_at, updated_at, body, user_id
+const listTrashedChirps = `-- name: ListTrashedChirps :many
+SELECT id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
 FROM chirps
+WHERE user_id = $1
+AND deleted_at IS NOT NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+AND ($2::timestamp IS NULL OR (deleted_at, id) < ($2::timestamp, $3::uuid))
+ORDER BY deleted_at DESC, id DESC
+LIMIT $4
+`
+
+type ListTrashedChirpsParams struct {
+	UserID          uuid.UUID
+	CursorCreatedAt sql.NullTime
+	CursorID        uuid.NullUUID
+	PageSize        int32
+}
+
+func (q *Queries) ListTrashedChirps(ctx context.Context, arg ListTrashedChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, listTrashedChirps,
+		arg.UserID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.RootID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.SearchVector,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ExpiresAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const publishDueChirps = `-- name: PublishDueChirps :many
+UPDATE chirps
+SET publish_at = NULL, created_at = NOW(), updated_at = NOW()
+WHERE id IN (
+    SELECT due.id
+    FROM chirps AS due
+    WHERE due.publish_at <= NOW()
+    AND due.deleted_at IS NULL
+    AND (due.expires_at IS NULL OR due.expires_at > NOW())
+    ORDER BY due.publish_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
+`
+
+func (q *Queries) PublishDueChirps(ctx context.Context, batchSize int32) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, publishDueChirps, batchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.ParentID,
+			&i.RootID,
+			&i.RechirpOf,
+			&i.QuoteOf,
+			&i.SearchVector,
+			&i.PublishAt,
+			&i.DeletedAt,
+			&i.ExpiresAt,
+			&i.Visibility,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rescheduleChirp = `-- name: RescheduleChirp :one
+UPDATE chirps
+SET publish_at = $1, updated_at = NOW()
+WHERE id = $2
+AND publish_at IS NOT NULL
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
+`
+
+type RescheduleChirpParams struct {
+	PublishAt sql.NullTime
+	ID        uuid.UUID
+}
+
+func (q *Queries) RescheduleChirp(ctx context.Context, arg RescheduleChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, rescheduleChirp, arg.PublishAt, arg.ID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.RootID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.SearchVector,
+		&i.PublishAt,
+		&i.DeletedAt,
+		&i.ExpiresAt,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const restoreChirp = `-- name: RestoreChirp :one
+UPDATE chirps
+SET deleted_at = NULL
 WHERE id = $1
+AND user_id = $2
+AND deleted_at IS NOT NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
 `
 
-func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, getChirp, id)
+type RestoreChirpParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) RestoreChirp(ctx context.Context, arg RestoreChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, restoreChirp, arg.ID, arg.UserID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.ParentID,
+		&i.RootID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.SearchVector,
+		&i.PublishAt,
+		&i.DeletedAt,
+		&i.ExpiresAt,
+		&i.Visibility,
+	)
+	return i, err
+}
+
+const softDeleteChirp = `-- name: SoftDeleteChirp :exec
+UPDATE chirps
+SET deleted_at = NOW()
+WHERE id = $1
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+`
+
+func (q *Queries) SoftDeleteChirp(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, softDeleteChirp, id)
+	return err
+}
+
+const updateChirpBody = `-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $1, updated_at = NOW()
+WHERE id = $2
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, search_vector, publish_at, deleted_at, expires_at, visibility
+`
+
+type UpdateChirpBodyParams struct {
+	Body string
+	ID   uuid.UUID
+}
+
+func (q *Queries) UpdateChirpBody(ctx context.Context, arg UpdateChirpBodyParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirpBody, arg.Body, arg.ID)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -138,6 +949,15 @@ func (q *Queries) GetChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.ParentID,
+		&i.RootID,
+		&i.RechirpOf,
+		&i.QuoteOf,
+		&i.SearchVector,
+		&i.PublishAt,
+		&i.DeletedAt,
+		&i.ExpiresAt,
+		&i.Visibility,
 	)
 	return i, err
 }
@@ -6,17 +6,133 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type Attachment struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UserID       uuid.UUID
+	ChirpID      uuid.NullUUID
+	ContentType  string
+	SizeBytes    int64
+	Width        int32
+	Height       int32
+	StorageKey   string
+	ThumbnailKey string
+}
+
+type Bookmark struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
 type Chirp struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Body         string
+	UserID       uuid.UUID
+	ParentID     uuid.NullUUID
+	RootID       uuid.NullUUID
+	RechirpOf    uuid.NullUUID
+	QuoteOf      uuid.NullUUID
+	SearchVector interface{}
+	PublishAt    sql.NullTime
+	DeletedAt    sql.NullTime
+	ExpiresAt    sql.NullTime
+	Visibility   string
+}
+
+type ChirpHashtag struct {
+	ChirpID   uuid.UUID
+	HashtagID uuid.UUID
+}
+
+type ChirpRevision struct {
+	ID        uuid.UUID
+	ChirpID   uuid.UUID
+	Body      string
+	CreatedAt time.Time
+}
+
+type Draft struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	UserID    uuid.UUID
 	Body      string
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+}
+
+type Hashtag struct {
+	ID        uuid.UUID
+	Tag       string
+	CreatedAt time.Time
+}
+
+type Like struct {
 	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type Mention struct {
+	ChirpID     uuid.UUID
+	UserID      uuid.UUID
+	StartOffset int32
+	EndOffset   int32
+	CreatedAt   time.Time
+}
+
+type ModerationFlag struct {
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+	Rules     []string
+}
+
+type ModerationRule struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	Kind      string
+	Pattern   string
+	Action    string
+}
+
+type PinnedChirp struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	Position  int32
+	CreatedAt time.Time
+}
+
+type Poll struct {
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type PollOption struct {
+	ID       uuid.UUID
+	ChirpID  uuid.UUID
+	Position int32
+	Text     string
+}
+
+type PollVote struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	OptionID  uuid.UUID
+	CreatedAt time.Time
 }
 
 type RefreshToken struct {
@@ -28,6 +144,17 @@ type RefreshToken struct {
 	RevokedAt sql.NullTime
 }
 
+type Subscription struct {
+	UserID              uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Plan                string
+	Status              string
+	CurrentPeriodStart  time.Time
+	CurrentPeriodEnd    sql.NullTime
+	PolkaSubscriptionID sql.NullString
+}
+
 type User struct {
 	ID             uuid.UUID
 	CreatedAt      time.Time
@@ -35,4 +162,43 @@ type User struct {
 	Email          string
 	HashedPassword string
 	IsChirpyRed    sql.NullBool
+	Handle         sql.NullString
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	EndpointID     uuid.UUID
+	CreatedAt      time.Time
+	EventType      string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	LastAttemptAt  sql.NullTime
+	LastStatusCode sql.NullInt32
+	LastError      sql.NullString
+}
+
+type WebhookEndpoint struct {
+	ID                  uuid.UUID
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	Url                 string
+	Secret              string
+	Events              []string
+	Enabled             bool
+	ConsecutiveFailures int32
+	DisabledAt          sql.NullTime
+}
+
+type WebhookEvent struct {
+	ID          uuid.UUID
+	EventID     string
+	EventType   string
+	Payload     json.RawMessage
+	ReceivedAt  time.Time
+	ProcessedAt sql.NullTime
+	Outcome     string
+	Error       sql.NullString
+	Attempts    int32
 }
@@ -0,0 +1,114 @@
+package entitlements
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Limits are what a plan lets its users do.
+type Limits struct {
+	MaxChirpLength int  `json:"max_chirp_length"`
+	MaxPins        int  `json:"max_pins"`
+	CanEdit        bool `json:"can_edit"`
+	// EditWindow is how long after posting a chirp may still be edited; zero means forever.
+	EditWindow Duration `json:"edit_window"`
+	// ChirpsPerHour caps how many chirps a user may create in any hour; zero means no cap.
+	ChirpsPerHour int `json:"chirps_per_hour"`
+}
+
+// Config holds the limits of the free plan and of Chirpy Red.
+type Config struct {
+	Free Limits `json:"free"`
+	Red  Limits `json:"red"`
+}
+
+func Default() Config {
+	return Config{
+		// Free users can fix a typo shortly after posting; Red users can edit whenever they like.
+		Free: Limits{
+			MaxChirpLength: 140,
+			MaxPins:        3,
+			CanEdit:        true,
+			EditWindow:     Duration(15 * time.Minute),
+			ChirpsPerHour:  50,
+		},
+		Red: Limits{
+			MaxChirpLength: 280,
+			MaxPins:        10,
+			CanEdit:        true,
+			ChirpsPerHour:  300,
+		},
+	}
+}
+
+// Load reads a JSON config on top of base, so the file only needs the fields it changes.
+func Load(r io.Reader, base Config) (Config, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&base); err != nil {
+		return Config{}, err
+	}
+	if err := base.Validate(); err != nil {
+		return Config{}, err
+	}
+	return base, nil
+}
+
+func (c Config) Validate() error {
+	if err := c.Free.validate(); err != nil {
+		return fmt.Errorf("free: %w", err)
+	}
+	if err := c.Red.validate(); err != nil {
+		return fmt.Errorf("red: %w", err)
+	}
+	return nil
+}
+
+func (l Limits) validate() error {
+	if l.MaxChirpLength <= 0 {
+		return errors.New("max_chirp_length must be positive")
+	}
+	if l.MaxPins < 0 || l.EditWindow < 0 || l.ChirpsPerHour < 0 {
+		return errors.New("limits can't be negative")
+	}
+	return nil
+}
+
+// For returns the limits of a user with or without Chirpy Red.
+func (c Config) For(chirpyRed bool) Limits {
+	if chirpyRed {
+		return c.Red
+	}
+	return c.Free
+}
+
+// CanEditChirp reports whether a chirp created at createdAt may be edited at now.
+func (l Limits) CanEditChirp(createdAt, now time.Time) bool {
+	if !l.CanEdit {
+		return false
+	}
+	return l.EditWindow == 0 || now.Sub(createdAt) <= time.Duration(l.EditWindow)
+}
+
+// Duration is a time.Duration written as a string such as "15m" in JSON.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
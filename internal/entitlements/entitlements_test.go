@@ -0,0 +1,69 @@
+package entitlements
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	config, err := Load(strings.NewReader(`{"red": {"max_chirp_length": 500, "edit_window": "1h"}}`), Default())
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if config.Red.MaxChirpLength != 500 || config.Red.EditWindow != Duration(time.Hour) {
+		t.Fatalf("Load didn't apply the file: %+v", config.Red)
+	}
+	if config.Red.MaxPins != Default().Red.MaxPins || config.Free != Default().Free {
+		t.Fatalf("Load dropped defaults the file didn't mention: %+v", config)
+	}
+
+	invalid := []string{
+		`{"free": {"max_chirp_length": 0}}`,
+		`{"red": {"max_pins": -1}}`,
+		`{"red": {"edit_window": "soon"}}`,
+		`{"gold": {}}`,
+	}
+	for _, input := range invalid {
+		if _, err := Load(strings.NewReader(input), Default()); err == nil {
+			t.Fatalf("Load(%s) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestCanEditChirp(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		limits Limits
+		after  time.Duration
+		want   bool
+	}{
+		{limits: Limits{CanEdit: true}, after: 365 * 24 * time.Hour, want: true},
+		{limits: Limits{CanEdit: true, EditWindow: Duration(time.Hour)}, after: time.Hour, want: true},
+		{limits: Limits{CanEdit: true, EditWindow: Duration(time.Hour)}, after: time.Hour + time.Second, want: false},
+		{limits: Limits{CanEdit: false}, after: 0, want: false},
+	}
+
+	for _, test := range tests {
+		if got := test.limits.CanEditChirp(createdAt, createdAt.Add(test.after)); got != test.want {
+			t.Fatalf("%+v.CanEditChirp after %v = %v, want %v", test.limits, test.after, got, test.want)
+		}
+	}
+}
+
+func TestDefaultRedEditsLonger(t *testing.T) {
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	later := createdAt.Add(24 * time.Hour)
+
+	config := Default()
+	if config.Free.CanEditChirp(createdAt, later) {
+		t.Fatalf("free plan can edit a day-old chirp, want it limited to its edit window")
+	}
+	if !config.Free.CanEditChirp(createdAt, createdAt.Add(time.Minute)) {
+		t.Fatalf("free plan can't edit a fresh chirp")
+	}
+	if !config.Red.CanEditChirp(createdAt, later) {
+		t.Fatalf("red plan can't edit a day-old chirp")
+	}
+}
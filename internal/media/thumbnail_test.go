@@ -0,0 +1,35 @@
+package media
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestThumbnailKeepsAspectRatio(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1000, 500))
+
+	thumb := Thumbnail(img, 200)
+	if got := thumb.Bounds(); got.Dx() != 200 || got.Dy() != 100 {
+		t.Fatalf("Thumbnail size = %v, want 200x100", got.Size())
+	}
+}
+
+func TestThumbnailAveragesPixels(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	img.Set(1, 0, color.RGBA{B: 255, A: 255})
+
+	r, g, b, _ := Thumbnail(img, 1).At(0, 0).RGBA()
+	if r>>8 != 127 || g != 0 || b>>8 != 127 {
+		t.Fatalf("Thumbnail pixel = %d %d %d, want an even mix of red and blue", r>>8, g>>8, b>>8)
+	}
+}
+
+func TestThumbnailReturnsSmallImagesUnchanged(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 50, 50))
+
+	if Thumbnail(img, 200) != image.Image(img) {
+		t.Fatalf("Thumbnail resized an image that already fits")
+	}
+}
@@ -0,0 +1,102 @@
+package moderation
+
+import (
+	"regexp"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// WordFilter matches whole words regardless of case, accents and compatibility forms,
+// so "Kerfuffle!", "kérfuffle" and fullwidth "ｋｅｒｆｕｆｆｌｅ" all match "kerfuffle".
+type WordFilter struct {
+	words map[string]Action
+}
+
+func NewWordFilter() *WordFilter {
+	return &WordFilter{words: map[string]Action{}}
+}
+
+func (f *WordFilter) Add(word string, action Action) {
+	f.words[Normalize(word)] = action
+}
+
+func (f *WordFilter) Find(text string) []Match {
+	matches := []Match{}
+	for _, word := range words(text) {
+		normalized := Normalize(text[word[0]:word[1]])
+		if action, ok := f.words[normalized]; ok {
+			matches = append(matches, Match{
+				Start:  word[0],
+				End:    word[1],
+				Rule:   normalized,
+				Action: action,
+			})
+		}
+	}
+	return matches
+}
+
+// Normalize folds a word to the form word rules are stored and compared in.
+func Normalize(word string) string {
+	// Transformers keep state, so the chain is built per call to stay safe for concurrent use.
+	normalizer := transform.Chain(norm.NFKD, runes.Remove(runes.In(unicode.Mn)), cases.Fold(), norm.NFC)
+	normalized, _, err := transform.String(normalizer, word)
+	if err != nil {
+		return word
+	}
+	return normalized
+}
+
+// words returns the byte ranges of the runs of letters and digits in text.
+func words(text string) [][2]int {
+	ranges := [][2]int{}
+	start := -1
+	for i, r := range text {
+		inWord := unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.Mn, r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			ranges = append(ranges, [2]int{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		ranges = append(ranges, [2]int{start, len(text)})
+	}
+	return ranges
+}
+
+type RegexFilter struct {
+	re     *regexp.Regexp
+	action Action
+}
+
+func NewRegexFilter(pattern string, action Action) (*RegexFilter, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return &RegexFilter{re: re, action: action}, nil
+}
+
+func (f *RegexFilter) Find(text string) []Match {
+	matches := []Match{}
+	for _, loc := range f.re.FindAllStringIndex(text, -1) {
+		// An empty match has nothing to mask and would flag every chirp.
+		if loc[0] == loc[1] {
+			continue
+		}
+		matches = append(matches, Match{
+			Start:  loc[0],
+			End:    loc[1],
+			Rule:   f.re.String(),
+			Action: f.action,
+		})
+	}
+	return matches
+}
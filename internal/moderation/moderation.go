@@ -0,0 +1,118 @@
+// Package moderation checks chirp text against a chain of filters and decides what happens to it.
+package moderation
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+type Action string
+
+const (
+	// ActionMask replaces the matched text with asterisks.
+	ActionMask Action = "mask"
+	// ActionFlag lets the text through but queues it for review.
+	ActionFlag Action = "flag"
+	// ActionReject refuses the text altogether.
+	ActionReject Action = "reject"
+)
+
+func ParseAction(action string) (Action, error) {
+	switch Action(action) {
+	case ActionMask, ActionFlag, ActionReject:
+		return Action(action), nil
+	}
+	return "", errors.New("action must be mask, flag or reject")
+}
+
+const mask = "****"
+
+// Match is a part of the text, as byte offsets, that a rule applies to.
+type Match struct {
+	Start  int
+	End    int
+	Rule   string
+	Action Action
+}
+
+// Filter is one link of the chain. Filters only find matches; the pipeline acts on them.
+type Filter interface {
+	Find(text string) []Match
+}
+
+type Verdict struct {
+	Rejected bool
+	Flagged  bool
+	// Rules lists the rejecting and flagging rules that matched, without duplicates.
+	Rules []string
+}
+
+func (v Verdict) Merge(other Verdict) Verdict {
+	merged := Verdict{
+		Rejected: v.Rejected || other.Rejected,
+		Flagged:  v.Flagged || other.Flagged,
+		Rules:    slices.Clone(v.Rules),
+	}
+	for _, rule := range other.Rules {
+		if !slices.Contains(merged.Rules, rule) {
+			merged.Rules = append(merged.Rules, rule)
+		}
+	}
+	return merged
+}
+
+type Pipeline struct {
+	filters []Filter
+}
+
+func NewPipeline(filters ...Filter) *Pipeline {
+	return &Pipeline{filters: filters}
+}
+
+// Check runs text through every filter and returns it with the masked parts replaced.
+func (p *Pipeline) Check(text string) (string, Verdict) {
+	verdict := Verdict{}
+	masked := []Match{}
+	for _, filter := range p.filters {
+		for _, match := range filter.Find(text) {
+			switch match.Action {
+			case ActionMask:
+				masked = append(masked, match)
+				continue
+			case ActionFlag:
+				verdict.Flagged = true
+			case ActionReject:
+				verdict.Rejected = true
+			}
+			if !slices.Contains(verdict.Rules, match.Rule) {
+				verdict.Rules = append(verdict.Rules, match.Rule)
+			}
+		}
+	}
+
+	return maskMatches(text, masked), verdict
+}
+
+func maskMatches(text string, matches []Match) string {
+	if len(matches) == 0 {
+		return text
+	}
+
+	slices.SortFunc(matches, func(a, b Match) int { return a.Start - b.Start })
+
+	var builder strings.Builder
+	last := 0
+	for _, match := range matches {
+		// Overlapping matches share one mask.
+		if match.Start < last {
+			last = max(last, match.End)
+			continue
+		}
+		builder.WriteString(text[last:match.Start])
+		builder.WriteString(mask)
+		last = match.End
+	}
+	builder.WriteString(text[last:])
+	return builder.String()
+}
@@ -0,0 +1,111 @@
+package moderation
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCheckMasksWords(t *testing.T) {
+	pipeline, err := Build([]Rule{
+		{Kind: KindWord, Pattern: "kerfuffle", Action: ActionMask},
+		{Kind: KindWord, Pattern: "sharbert", Action: ActionMask},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "I had a kerfuffle today", want: "I had a **** today"},
+		{text: "What a kerfuffle!", want: "What a ****!"},
+		{text: "Kerfuffle, SHARBERT.", want: "****, ****."},
+		{text: "a kérfuffle", want: "a ****"},
+		{text: "ｋｅｒｆｕｆｆｌｅ", want: "****"},
+		{text: "kerfuffles are fine", want: "kerfuffles are fine"},
+	}
+
+	for _, test := range tests {
+		got, verdict := pipeline.Check(test.text)
+		if got != test.want {
+			t.Fatalf("Check(%q) = %q, want %q", test.text, got, test.want)
+		}
+		if verdict.Rejected || verdict.Flagged {
+			t.Fatalf("Check(%q) verdict = %+v, want only masking", test.text, verdict)
+		}
+	}
+}
+
+func TestCheckVerdict(t *testing.T) {
+	pipeline, err := Build([]Rule{
+		{Kind: KindWord, Pattern: "fornax", Action: ActionFlag},
+		{Kind: KindRegex, Pattern: `(?i)buy\s+now`, Action: ActionReject},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	text, verdict := pipeline.Check("fornax says BUY  now")
+	if text != "fornax says BUY  now" {
+		t.Fatalf("Check changed text to %q", text)
+	}
+	if !verdict.Flagged || !verdict.Rejected {
+		t.Fatalf("verdict = %+v, want flagged and rejected", verdict)
+	}
+	if want := []string{"fornax", `(?i)buy\s+now`}; !slices.Equal(verdict.Rules, want) {
+		t.Fatalf("rules = %q, want %q", verdict.Rules, want)
+	}
+}
+
+func TestCheckOverlappingMasks(t *testing.T) {
+	pipeline, err := Build([]Rule{
+		{Kind: KindWord, Pattern: "kerfuffle", Action: ActionMask},
+		{Kind: KindRegex, Pattern: `fuffle \w+`, Action: ActionMask},
+	})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	if got, _ := pipeline.Check("a kerfuffle happened here"); got != "a **** here" {
+		t.Fatalf("Check = %q", got)
+	}
+}
+
+func TestBuildRejectsInvalidRules(t *testing.T) {
+	rules := []Rule{
+		{Kind: KindWord, Pattern: "two words", Action: ActionMask},
+		{Kind: KindWord, Pattern: "ok", Action: "delete"},
+		{Kind: KindRegex, Pattern: "(", Action: ActionMask},
+		{Kind: "phrase", Pattern: "ok", Action: ActionMask},
+	}
+
+	for _, rule := range rules {
+		if _, err := Build([]Rule{rule}); err == nil {
+			t.Fatalf("Build(%+v) succeeded, want error", rule)
+		}
+	}
+}
+
+func TestParseWordList(t *testing.T) {
+	list := "# defaults\nkerfuffle\n\nfornax flag\n  spam reject  \n"
+
+	rules, err := ParseWordList(strings.NewReader(list))
+	if err != nil {
+		t.Fatalf("ParseWordList: %v", err)
+	}
+
+	want := []Rule{
+		{Kind: KindWord, Pattern: "kerfuffle", Action: ActionMask},
+		{Kind: KindWord, Pattern: "fornax", Action: ActionFlag},
+		{Kind: KindWord, Pattern: "spam", Action: ActionReject},
+	}
+	if !slices.Equal(rules, want) {
+		t.Fatalf("ParseWordList = %+v, want %+v", rules, want)
+	}
+
+	if _, err := ParseWordList(strings.NewReader("word mask extra\n")); err == nil {
+		t.Fatalf("ParseWordList accepted a line with too many fields")
+	}
+}
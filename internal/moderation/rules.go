@@ -0,0 +1,95 @@
+package moderation
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+type Kind string
+
+const (
+	KindWord  Kind = "word"
+	KindRegex Kind = "regex"
+)
+
+type Rule struct {
+	Kind    Kind
+	Pattern string
+	Action  Action
+}
+
+// Validate reports whether the rule can be built, so bad rules are refused before they are stored.
+func (r Rule) Validate() error {
+	if _, err := ParseAction(string(r.Action)); err != nil {
+		return err
+	}
+
+	switch r.Kind {
+	case KindWord:
+		if len(words(r.Pattern)) != 1 {
+			return errors.New("word rule must be a single word")
+		}
+	case KindRegex:
+		if _, err := regexp.Compile(r.Pattern); err != nil {
+			return err
+		}
+	default:
+		return errors.New("kind must be word or regex")
+	}
+	return nil
+}
+
+// Build turns rules into a pipeline: one word filter for all the words, then a filter per regex.
+func Build(rules []Rule) (*Pipeline, error) {
+	wordFilter := NewWordFilter()
+	filters := []Filter{wordFilter}
+
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("%s rule %q: %w", rule.Kind, rule.Pattern, err)
+		}
+		switch rule.Kind {
+		case KindWord:
+			wordFilter.Add(rule.Pattern, rule.Action)
+		case KindRegex:
+			filter, err := NewRegexFilter(rule.Pattern, rule.Action)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, filter)
+		}
+	}
+
+	return NewPipeline(filters...), nil
+}
+
+// ParseWordList reads one word per line, optionally followed by an action (mask by default).
+// Blank lines and lines starting with # are skipped.
+func ParseWordList(r io.Reader) ([]Rule, error) {
+	rules := []Rule{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("line %d: expected a word and an optional action", line)
+		}
+
+		rule := Rule{Kind: KindWord, Pattern: fields[0], Action: ActionMask}
+		if len(fields) == 2 {
+			rule.Action = Action(fields[1])
+		}
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, scanner.Err()
+}
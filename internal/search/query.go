@@ -0,0 +1,53 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ToTSQuery turns free-form search input into a to_tsquery expression.
+// Words are ANDed together, "quoted phrases" must appear in order and a
+// trailing * turns a word into a prefix match. Everything except letters
+// and digits is dropped so the result is always valid tsquery syntax.
+func ToTSQuery(input string) string {
+	terms := []string{}
+
+	for i, segment := range strings.Split(input, `"`) {
+		lexemes := toLexemes(segment)
+		if len(lexemes) == 0 {
+			continue
+		}
+
+		// Odd segments are the ones between quotes.
+		if i%2 == 1 && len(lexemes) > 1 {
+			terms = append(terms, "("+strings.Join(lexemes, " <-> ")+")")
+			continue
+		}
+		terms = append(terms, lexemes...)
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+func toLexemes(segment string) []string {
+	lexemes := []string{}
+
+	words := strings.FieldsFunc(segment, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '*'
+	})
+	for _, word := range words {
+		prefix := strings.HasSuffix(word, "*")
+		word = strings.ReplaceAll(word, "*", "")
+		if word == "" {
+			continue
+		}
+
+		word = strings.ToLower(word)
+		if prefix {
+			word += ":*"
+		}
+		lexemes = append(lexemes, word)
+	}
+
+	return lexemes
+}
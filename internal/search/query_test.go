@@ -0,0 +1,26 @@
+package search
+
+import "testing"
+
+func TestToTSQuery(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "hello world", want: "hello & world"},
+		{input: `"hello world" again`, want: "(hello <-> world) & again"},
+		{input: "chirp*", want: "chirp:*"},
+		{input: `"big chir*"`, want: "(big <-> chir:*)"},
+		{input: "drop & table | !users:*", want: "drop & table & users"},
+		{input: "Zażółć gęślą", want: "zażółć & gęślą"},
+		{input: `"single"`, want: "single"},
+		{input: `"unterminated phrase`, want: "(unterminated <-> phrase)"},
+		{input: " * !! ", want: ""},
+	}
+
+	for _, test := range tests {
+		if got := ToTSQuery(test.input); got != test.want {
+			t.Fatalf("ToTSQuery(%q) = %q, want %q", test.input, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"io/fs"
+	"net/http"
+	"strings"
+)
+
+// FilesOnly wraps an http.FileSystem so directories can't be opened, which
+// keeps http.FileServer from listing the blobs stored under them.
+func FilesOnly(fsys http.FileSystem) http.FileSystem {
+	return filesOnly{fsys}
+}
+
+type filesOnly struct {
+	fsys http.FileSystem
+}
+
+func (f filesOnly) Open(name string) (http.File, error) {
+	if strings.HasSuffix(name, "/") {
+		return nil, fs.ErrNotExist
+	}
+	file, err := f.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, fs.ErrNotExist
+	}
+	return file, nil
+}
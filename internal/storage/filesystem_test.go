@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilesOnlyRefusesDirectories(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "attachments"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "attachments", "a.png"), []byte("data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	server := http.FileServer(FilesOnly(http.Dir(root)))
+
+	for path, want := range map[string]int{
+		"/attachments/a.png": http.StatusOK,
+		"/attachments/":      http.StatusNotFound,
+		"/attachments":       http.StatusNotFound,
+		"/":                  http.StatusNotFound,
+		"/missing.png":       http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
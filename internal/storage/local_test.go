@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorePutDelete(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocalStore(root, "/media/")
+	if err != nil {
+		t.Fatalf("NewLocalStore failed: %v", err)
+	}
+
+	if err := store.Put(context.Background(), "attachments/a.png", strings.NewReader("data")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(root, "attachments", "a.png"))
+	if err != nil || string(content) != "data" {
+		t.Fatalf("stored blob = %q, %v", content, err)
+	}
+
+	if url := store.URL("attachments/a.png"); url != "/media/attachments/a.png" {
+		t.Fatalf("URL = %q", url)
+	}
+
+	if err := store.Delete(context.Background(), "attachments/a.png"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := store.Delete(context.Background(), "attachments/a.png"); err != nil {
+		t.Fatalf("Delete of a missing blob failed: %v", err)
+	}
+}
+
+func TestLocalStoreRejectsEscapingKeys(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), "/media")
+	if err != nil {
+		t.Fatalf("NewLocalStore failed: %v", err)
+	}
+
+	for _, key := range []string{"../secret", "/etc/passwd", "a/../../b", ""} {
+		if err := store.Put(context.Background(), key, strings.NewReader("x")); !errors.Is(err, ErrInvalidKey) {
+			t.Fatalf("Put(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
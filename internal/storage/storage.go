@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// BlobStore keeps uploaded files. Keys are slash-separated relative paths
+// such as "attachments/<id>.png"; URL returns where clients can fetch them.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
@@ -0,0 +1,36 @@
+// Package webhooks decides how failed outbound webhook deliveries are retried and when an
+// endpoint that keeps failing is given up on.
+package webhooks
+
+import "time"
+
+const (
+	// MaxAttempts is how many times a delivery is tried before it's marked failed.
+	MaxAttempts = 10
+	// EndpointFailureLimit is how many failed attempts in a row, across all of an endpoint's
+	// deliveries, disable it.
+	EndpointFailureLimit = 25
+
+	backoffBase = 30 * time.Second
+	backoffMax  = 6 * time.Hour
+)
+
+// Backoff is how long to wait after the given number of failed attempts. It doubles with
+// every attempt, starting at 30 seconds and capped at 6 hours.
+func Backoff(attempts int32) time.Duration {
+	backoff := backoffBase
+	for i := int32(1); i < attempts && backoff < backoffMax; i++ {
+		backoff *= 2
+	}
+	return min(backoff, backoffMax)
+}
+
+// Exhausted reports whether a delivery that has failed this many times should stop being retried.
+func Exhausted(attempts int32) bool {
+	return attempts >= MaxAttempts
+}
+
+// DisableEndpoint reports whether an endpoint with this many failures in a row should be disabled.
+func DisableEndpoint(consecutiveFailures int32) bool {
+	return consecutiveFailures >= EndpointFailureLimit
+}
@@ -0,0 +1,45 @@
+package webhooks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	cases := map[int32]time.Duration{
+		0:    30 * time.Second,
+		1:    30 * time.Second,
+		2:    time.Minute,
+		3:    2 * time.Minute,
+		9:    128 * time.Minute,
+		10:   256 * time.Minute,
+		11:   6 * time.Hour,
+		1000: 6 * time.Hour,
+	}
+	for attempts, want := range cases {
+		if got := Backoff(attempts); got != want {
+			t.Errorf("Backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestExhausted(t *testing.T) {
+	if Exhausted(MaxAttempts - 1) {
+		t.Fatalf("Exhausted(%d) = true, want another attempt", MaxAttempts-1)
+	}
+	if !Exhausted(MaxAttempts) {
+		t.Fatalf("Exhausted(%d) = false, want the delivery given up on", MaxAttempts)
+	}
+}
+
+func TestDisableEndpoint(t *testing.T) {
+	if DisableEndpoint(EndpointFailureLimit - 1) {
+		t.Fatalf("DisableEndpoint(%d) = true, want the endpoint kept", EndpointFailureLimit-1)
+	}
+	if !DisableEndpoint(EndpointFailureLimit) {
+		t.Fatalf("DisableEndpoint(%d) = false, want the endpoint disabled", EndpointFailureLimit)
+	}
+	if !DisableEndpoint(EndpointFailureLimit + 5) {
+		t.Fatalf("DisableEndpoint(%d) = false, want the endpoint disabled", EndpointFailureLimit+5)
+	}
+}
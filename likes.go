@@ -0,0 +1,139 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/auth"
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerLike(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing chirpID error", err)
+		return
+	}
+
+	if _, err := cfg.getPublishedChirp(req.Context(), chirpID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No chirp error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Getting chirp error", err)
+		return
+	}
+
+	if err := cfg.database.CreateLike(req.Context(), database.CreateLikeParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Liking error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnlike(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing chirpID error", err)
+		return
+	}
+
+	if err := cfg.database.DeleteLike(req.Context(), database.DeleteLikeParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unliking error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerGetUserLikes(w http.ResponseWriter, req *http.Request) {
+	userID, err := uuid.Parse(req.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing userID error", err)
+		return
+	}
+
+	viewerID, err := cfg.optionalUserID(req)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	pageSize, err := parsePageSize(req.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := decodeCursor(req.URL.Query().Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	rows, err := cfg.database.ListLikedChirps(req.Context(), database.ListLikedChirpsParams{
+		UserID:          userID,
+		ViewerID:        viewerID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        pageSize + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting likes error", err)
+		return
+	}
+
+	rows, nextCursor := nextPage(rows, pageSize, func(row database.ListLikedChirpsRow) (time.Time, uuid.UUID) {
+		return row.LikedAt, row.Chirp.ID
+	})
+
+	chirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, row.Chirp)
+	}
+
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), viewerID, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting likes error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ChirpPage{
+		Chirps:     chirpsJSON,
+		NextCursor: nextCursor,
+	})
+}
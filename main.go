@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/Mielecki/Chirpy/internal/entitlements"
+	"github.com/Mielecki/Chirpy/internal/moderation"
+	"github.com/Mielecki/Chirpy/internal/storage"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 )
@@ -26,29 +32,160 @@ func main(){
 		log.Fatal(err)
 	}
 
+	entitlementsConfig := entitlements.Default()
+	if window := os.Getenv("EDIT_WINDOW"); window != "" {
+		editWindow, err := time.ParseDuration(window)
+		if err != nil {
+			log.Fatalf("EDIT_WINDOW must be a duration: %v", err)
+		}
+		entitlementsConfig.Free.EditWindow = entitlements.Duration(editWindow)
+	}
+	if entitlementsFile := os.Getenv("ENTITLEMENTS_FILE"); entitlementsFile != "" {
+		f, err := os.Open(entitlementsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		entitlementsConfig, err = entitlements.Load(f, entitlementsConfig)
+		f.Close()
+		if err != nil {
+			log.Fatalf("ENTITLEMENTS_FILE is invalid: %v", err)
+		}
+	}
+
+	trashRetention := 30 * 24 * time.Hour
+	if retention := os.Getenv("TRASH_RETENTION"); retention != "" {
+		trashRetention, err = time.ParseDuration(retention)
+		if err != nil {
+			log.Fatalf("TRASH_RETENTION must be a duration: %v", err)
+		}
+	}
+
+	mediaRoot := os.Getenv("MEDIA_ROOT")
+	if mediaRoot == "" {
+		mediaRoot = "./media"
+	}
+	blobStore, err := storage.NewLocalStore(mediaRoot, "/media")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var moderationFileRules []moderation.Rule
+	if wordsFile := os.Getenv("MODERATION_WORDS_FILE"); wordsFile != "" {
+		f, err := os.Open(wordsFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		moderationFileRules, err = moderation.ParseWordList(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("MODERATION_WORDS_FILE is invalid: %v", err)
+		}
+	}
+
+	// Several comma-separated secrets can be active at once while one is being rotated out.
+	polkaSecrets := []string{}
+	for _, secret := range strings.Split(os.Getenv("POLKA_WEBHOOK_SECRETS"), ",") {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			polkaSecrets = append(polkaSecrets, secret)
+		}
+	}
+
 	cfg := apiConfig{
 		fileserverHits: atomic.Int32{},
+		db: db,
 		database: database.New(db),
 		platform: os.Getenv("PLATFORM"),
 		secret: os.Getenv("SECRET"),
 		polkaKey: os.Getenv("POLKA_KEY"),
+		polkaSecrets: polkaSecrets,
+		// Redirects aren't followed, so a delivery goes only where the endpoint says.
+		webhookClient: &http.Client{
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		entitlements: entitlementsConfig,
+		trashRetention: trashRetention,
+		storage: blobStore,
+		mediaFiles: http.StripPrefix("/media", http.FileServer(storage.FilesOnly(http.Dir(mediaRoot)))),
+		adminKey: os.Getenv("ADMIN_KEY"),
+		moderationFileRules: moderationFileRules,
+	}
+	if err := cfg.reloadModeration(context.Background()); err != nil {
+		log.Fatalf("Loading moderation rules: %v", err)
 	}
 
 	serveMux := http.NewServeMux()
 	serveMux.Handle("/app/", cfg.middlewareMetricsInc(http.StripPrefix("/app", http.FileServer(http.Dir(filepathRoot)))))
+	serveMux.HandleFunc("GET /media/{key...}", cfg.handlerGetMedia)
 	serveMux.HandleFunc("GET /admin/metrics", cfg.handlerMetrics)
 	serveMux.HandleFunc("POST /admin/reset", cfg.handlerReset)
+	serveMux.HandleFunc("GET /admin/moderation/rules", cfg.handlerGetModerationRules)
+	serveMux.HandleFunc("POST /admin/moderation/rules", cfg.handlerCreateModerationRule)
+	serveMux.HandleFunc("DELETE /admin/moderation/rules/{ruleID}", cfg.handlerDeleteModerationRule)
+	serveMux.HandleFunc("GET /admin/moderation/flags", cfg.handlerGetFlaggedChirps)
+	serveMux.HandleFunc("DELETE /admin/moderation/flags/{chirpID}", cfg.handlerDismissFlag)
+	serveMux.HandleFunc("GET /admin/webhooks/events", cfg.handlerGetWebhookEvents)
+	serveMux.HandleFunc("POST /admin/webhooks/events/{eventID}/replay", cfg.handlerReplayWebhookEvent)
+	serveMux.HandleFunc("GET /admin/webhooks/endpoints", cfg.handlerGetWebhookEndpoints)
+	serveMux.HandleFunc("POST /admin/webhooks/endpoints", cfg.handlerCreateWebhookEndpoint)
+	serveMux.HandleFunc("PUT /admin/webhooks/endpoints/{endpointID}", cfg.handlerUpdateWebhookEndpoint)
+	serveMux.HandleFunc("DELETE /admin/webhooks/endpoints/{endpointID}", cfg.handlerDeleteWebhookEndpoint)
+	serveMux.HandleFunc("GET /admin/webhooks/endpoints/{endpointID}/deliveries", cfg.handlerGetWebhookDeliveries)
 	serveMux.HandleFunc("GET /api/healthz", handlerReadiness)
 	serveMux.HandleFunc("POST /api/users", cfg.handlerUsers)
 	serveMux.HandleFunc("POST /api/chirps", cfg.handlerCreateChirp)
+	serveMux.HandleFunc("POST /api/chirps/validate", cfg.handlerValidateChirp)
 	serveMux.HandleFunc("GET /api/chirps", cfg.handlerGetChirps)
+	serveMux.HandleFunc("GET /api/chirps/scheduled", cfg.handlerGetScheduledChirps)
 	serveMux.HandleFunc("GET /api/chirps/{chirpID}", cfg.handlerGetChirp)
+	serveMux.HandleFunc("GET /api/chirps/{chirpID}/thread", cfg.handlerGetThread)
 	serveMux.HandleFunc("POST /api/login", cfg.handlerLogin)
 	serveMux.HandleFunc("POST /api/refresh", cfg.handlerRefresh)
 	serveMux.HandleFunc("POST /api/revoke", cfg.handlerRevoke)
 	serveMux.HandleFunc("PUT /api/users", cfg.handlerUpdateUser)
+	serveMux.HandleFunc("GET /api/users/me/subscription", cfg.handlerGetSubscription)
+	serveMux.HandleFunc("PUT /api/chirps/{chirpID}", cfg.handlerUpdateChirp)
 	serveMux.HandleFunc("DELETE /api/chirps/{chirpID}", cfg.handlerDelete)
+	serveMux.HandleFunc("GET /api/chirps/{chirpID}/revisions", cfg.handlerGetChirpRevisions)
 	serveMux.HandleFunc("POST /api/polka/webhooks", cfg.handlerPolka)
+	serveMux.HandleFunc("POST /api/users/{userID}/follow", cfg.handlerFollow)
+	serveMux.HandleFunc("DELETE /api/users/{userID}/follow", cfg.handlerUnfollow)
+	serveMux.HandleFunc("GET /api/users/{userID}/followers", cfg.handlerGetFollowers)
+	serveMux.HandleFunc("GET /api/users/{userID}/following", cfg.handlerGetFollowing)
+	serveMux.HandleFunc("GET /api/timeline", cfg.handlerTimeline)
+	serveMux.HandleFunc("POST /api/chirps/{chirpID}/likes", cfg.handlerLike)
+	serveMux.HandleFunc("DELETE /api/chirps/{chirpID}/likes", cfg.handlerUnlike)
+	serveMux.HandleFunc("GET /api/users/{userID}/likes", cfg.handlerGetUserLikes)
+	serveMux.HandleFunc("GET /api/search/chirps", cfg.handlerSearchChirps)
+	serveMux.HandleFunc("GET /api/hashtags/trending", cfg.handlerGetTrendingHashtags)
+	serveMux.HandleFunc("GET /api/hashtags/{tag}/chirps", cfg.handlerGetHashtagChirps)
+	serveMux.HandleFunc("GET /api/mentions", cfg.handlerGetMentions)
+	serveMux.HandleFunc("POST /api/attachments", cfg.handlerUploadAttachment)
+	serveMux.HandleFunc("PUT /api/chirps/{chirpID}/schedule", cfg.handlerRescheduleChirp)
+	serveMux.HandleFunc("DELETE /api/chirps/{chirpID}/schedule", cfg.handlerCancelScheduledChirp)
+	serveMux.HandleFunc("POST /api/drafts", cfg.handlerCreateDraft)
+	serveMux.HandleFunc("GET /api/drafts", cfg.handlerGetDrafts)
+	serveMux.HandleFunc("GET /api/drafts/{draftID}", cfg.handlerGetDraft)
+	serveMux.HandleFunc("PUT /api/drafts/{draftID}", cfg.handlerUpdateDraft)
+	serveMux.HandleFunc("DELETE /api/drafts/{draftID}", cfg.handlerDeleteDraft)
+	serveMux.HandleFunc("POST /api/drafts/{draftID}/publish", cfg.handlerPublishDraft)
+	serveMux.HandleFunc("POST /api/chirps/{chirpID}/poll/votes", cfg.handlerVote)
+	serveMux.HandleFunc("POST /api/chirps/{chirpID}/bookmark", cfg.handlerBookmark)
+	serveMux.HandleFunc("DELETE /api/chirps/{chirpID}/bookmark", cfg.handlerRemoveBookmark)
+	serveMux.HandleFunc("GET /api/bookmarks", cfg.handlerGetBookmarks)
+	serveMux.HandleFunc("POST /api/chirps/{chirpID}/pin", cfg.handlerPin)
+	serveMux.HandleFunc("DELETE /api/chirps/{chirpID}/pin", cfg.handlerUnpin)
+	serveMux.HandleFunc("PUT /api/pins", cfg.handlerReorderPins)
+	serveMux.HandleFunc("GET /api/trash", cfg.handlerGetTrash)
+	serveMux.HandleFunc("POST /api/chirps/{chirpID}/restore", cfg.handlerRestoreChirp)
+
+	go runPeriodically(context.Background(), "chirp publisher", publisherInterval, cfg.publishDueChirps)
+	go runPeriodically(context.Background(), "trash purger", purgerInterval, cfg.purgeTrash)
+	go runPeriodically(context.Background(), "expired chirp sweeper", sweeperInterval, cfg.sweepExpiredChirps)
+	go runPeriodically(context.Background(), "webhook deliverer", delivererInterval, cfg.deliverWebhooks)
+	go runPeriodically(context.Background(), "subscription sweeper", subscriptionSweeperInterval, cfg.sweepExpiredSubscriptions)
+	go runPeriodically(context.Background(), "moderation reloader", moderationReloadInterval, cfg.reloadModeration)
 
 	server := http.Server{Handler: serveMux, Addr: ":" + port}
 	err = server.ListenAndServe()
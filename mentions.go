@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/auth"
+	"github.com/Mielecki/Chirpy/internal/chirptext"
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// Start and End are byte offsets into the chirp body, End exclusive.
+type MentionEntity struct {
+	UserID uuid.UUID `json:"user_id"`
+	Handle string    `json:"handle"`
+	Start  int32     `json:"start"`
+	End    int32     `json:"end"`
+}
+
+// saveMentions stores the @handles of a chirp that belong to existing users; unknown handles stay plain text.
+func saveMentions(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	mentions := chirptext.ExtractMentions(chirp.Body)
+	if len(mentions) == 0 {
+		return nil
+	}
+
+	handles := make([]string, 0, len(mentions))
+	for _, mention := range mentions {
+		handles = append(handles, mention.Handle)
+	}
+
+	users, err := q.GetUsersByHandles(ctx, handles)
+	if err != nil {
+		return err
+	}
+	userIDs := map[string]uuid.UUID{}
+	for _, user := range users {
+		userIDs[user.Handle.String] = user.ID
+	}
+
+	params := database.CreateMentionsParams{ChirpID: chirp.ID}
+	for _, mention := range mentions {
+		userID, ok := userIDs[mention.Handle]
+		if !ok {
+			continue
+		}
+		params.UserIds = append(params.UserIds, userID)
+		params.StartOffsets = append(params.StartOffsets, int32(mention.Start))
+		params.EndOffsets = append(params.EndOffsets, int32(mention.End))
+	}
+	if len(params.UserIds) == 0 {
+		return nil
+	}
+
+	return q.CreateMentions(ctx, params)
+}
+
+func (cfg *apiConfig) handlerGetMentions(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	pageSize, err := parsePageSize(req.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := decodeCursor(req.URL.Query().Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	chirps, err := cfg.database.ListMentioningChirps(req.Context(), database.ListMentioningChirpsParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        pageSize + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting mentions error", err)
+		return
+	}
+
+	chirps, nextCursor := nextPage(chirps, pageSize, func(chirp database.Chirp) (time.Time, uuid.UUID) {
+		return chirp.CreatedAt, chirp.ID
+	})
+
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), uuid.NullUUID{UUID: userID, Valid: true}, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting mentions error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ChirpPage{
+		Chirps:     chirpsJSON,
+		NextCursor: nextCursor,
+	})
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/Mielecki/Chirpy/internal/moderation"
+	"github.com/google/uuid"
+)
+
+const moderationReloadInterval = time.Minute
+
+type ModerationRule struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Kind      string    `json:"kind"`
+	Pattern   string    `json:"pattern"`
+	Action    string    `json:"action"`
+}
+
+type FlaggedChirp struct {
+	Chirp     Chirp     `json:"chirp"`
+	Rules     []string  `json:"rules"`
+	FlaggedAt time.Time `json:"flagged_at"`
+}
+
+type FlaggedChirpPage struct {
+	Chirps     []FlaggedChirp `json:"chirps"`
+	NextCursor *string        `json:"next_cursor"`
+}
+
+// reloadModeration rebuilds the pipeline from the word list file and the rules table. Admin changes
+// reload right away on the instance that made them; the others catch up on their next periodic reload.
+func (cfg *apiConfig) reloadModeration(ctx context.Context) error {
+	rows, err := cfg.database.ListModerationRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	rules := slices.Clone(cfg.moderationFileRules)
+	for _, row := range rows {
+		rules = append(rules, moderation.Rule{
+			Kind:    moderation.Kind(row.Kind),
+			Pattern: row.Pattern,
+			Action:  moderation.Action(row.Action),
+		})
+	}
+
+	pipeline, err := moderation.Build(rules)
+	if err != nil {
+		return err
+	}
+	cfg.moderation.Store(pipeline)
+	return nil
+}
+
+// moderate masks every text in place and returns the combined verdict.
+func (cfg *apiConfig) moderate(texts ...*string) moderation.Verdict {
+	pipeline := cfg.moderation.Load()
+	verdict := moderation.Verdict{}
+	for _, text := range texts {
+		checked, textVerdict := pipeline.Check(*text)
+		*text = checked
+		verdict = verdict.Merge(textVerdict)
+	}
+	return verdict
+}
+
+func flagChirp(ctx context.Context, qtx *database.Queries, chirpID uuid.UUID, verdict moderation.Verdict) error {
+	if !verdict.Flagged {
+		return nil
+	}
+	return qtx.FlagChirp(ctx, database.FlagChirpParams{
+		ChirpID: chirpID,
+		Rules:   verdict.Rules,
+	})
+}
+
+func (cfg *apiConfig) handlerGetModerationRules(w http.ResponseWriter, req *http.Request) {
+	if err := cfg.checkAdminKey(req); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid admin key", err)
+		return
+	}
+
+	rows, err := cfg.database.ListModerationRules(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting rules error", err)
+		return
+	}
+
+	rules := []ModerationRule{}
+	for _, row := range rows {
+		rules = append(rules, ModerationRule(row))
+	}
+
+	respondWithJSON(w, http.StatusOK, rules)
+}
+
+func (cfg *apiConfig) handlerCreateModerationRule(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		Kind    string `json:"kind"`
+		Pattern string `json:"pattern"`
+		Action  string `json:"action"`
+	}
+
+	if err := cfg.checkAdminKey(req); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid admin key", err)
+		return
+	}
+
+	data := parameters{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&data); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Decoding error", err)
+		return
+	}
+
+	rule := moderation.Rule{
+		Kind:    moderation.Kind(data.Kind),
+		Pattern: data.Pattern,
+		Action:  moderation.Action(data.Action),
+	}
+	if err := rule.Validate(); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid rule: "+err.Error(), err)
+		return
+	}
+	if rule.Kind == moderation.KindWord {
+		rule.Pattern = moderation.Normalize(rule.Pattern)
+	}
+
+	row, err := cfg.database.CreateModerationRule(req.Context(), database.CreateModerationRuleParams{
+		Kind:    string(rule.Kind),
+		Pattern: rule.Pattern,
+		Action:  string(rule.Action),
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "Rule already exists", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Creating rule error", err)
+		return
+	}
+
+	if err := cfg.reloadModeration(req.Context()); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Reloading rules error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, ModerationRule(row))
+}
+
+func (cfg *apiConfig) handlerDeleteModerationRule(w http.ResponseWriter, req *http.Request) {
+	if err := cfg.checkAdminKey(req); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid admin key", err)
+		return
+	}
+
+	ruleID, err := uuid.Parse(req.PathValue("ruleID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing ruleID error", err)
+		return
+	}
+
+	deleted, err := cfg.database.DeleteModerationRule(req.Context(), ruleID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Deleting rule error", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "No rule error", nil)
+		return
+	}
+
+	if err := cfg.reloadModeration(req.Context()); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Reloading rules error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Flagged chirps are listed oldest first, so reviewers work through the queue in order.
+func (cfg *apiConfig) handlerGetFlaggedChirps(w http.ResponseWriter, req *http.Request) {
+	if err := cfg.checkAdminKey(req); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid admin key", err)
+		return
+	}
+
+	pageSize, err := parsePageSize(req.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := decodeCursor(req.URL.Query().Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	rows, err := cfg.database.ListFlaggedChirps(req.Context(), database.ListFlaggedChirpsParams{
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        pageSize + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting flagged chirps error", err)
+		return
+	}
+
+	rows, nextCursor := nextPage(rows, pageSize, func(row database.ListFlaggedChirpsRow) (time.Time, uuid.UUID) {
+		return row.FlaggedAt, row.Chirp.ID
+	})
+
+	flagged := []FlaggedChirp{}
+	for _, row := range rows {
+		flagged = append(flagged, FlaggedChirp{
+			Chirp:     chirpFromDatabase(row.Chirp),
+			Rules:     row.Rules,
+			FlaggedAt: row.FlaggedAt,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, FlaggedChirpPage{
+		Chirps:     flagged,
+		NextCursor: nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerDismissFlag(w http.ResponseWriter, req *http.Request) {
+	if err := cfg.checkAdminKey(req); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid admin key", err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing chirpID error", err)
+		return
+	}
+
+	dismissed, err := cfg.database.DeleteModerationFlag(req.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Dismissing flag error", err)
+		return
+	}
+	if dismissed == 0 {
+		respondWithError(w, http.StatusNotFound, "No flag error", sql.ErrNoRows)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
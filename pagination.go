@@ -0,0 +1,105 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Cursors are opaque to clients: base64 of "<created_at>|<id>" of the last item on a page.
+func encodeCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (sql.NullTime, uuid.NullUUID, error) {
+	if cursor == "" {
+		return sql.NullTime{}, uuid.NullUUID{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return sql.NullTime{}, uuid.NullUUID{}, err
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return sql.NullTime{}, uuid.NullUUID{}, errors.New("malformed cursor")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return sql.NullTime{}, uuid.NullUUID{}, err
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return sql.NullTime{}, uuid.NullUUID{}, err
+	}
+
+	return sql.NullTime{Time: createdAt, Valid: true}, uuid.NullUUID{UUID: id, Valid: true}, nil
+}
+
+// Ranked results have no stable sort key to seek on, so their cursor is just an encoded offset.
+func encodeOffsetCursor(offset int32) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(int(offset))))
+}
+
+func decodeOffsetCursor(cursor string) (int32, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	// Parsed at the width of the OFFSET parameter, so an oversized cursor is an error instead of wrapping.
+	offset, err := strconv.ParseInt(string(raw), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+
+	return int32(offset), nil
+}
+
+func parsePageSize(limit string) (int32, error) {
+	if limit == "" {
+		return defaultPageSize, nil
+	}
+
+	size, err := strconv.Atoi(limit)
+	if err != nil {
+		return 0, err
+	}
+	if size < 1 || size > maxPageSize {
+		return 0, errors.New("limit out of range")
+	}
+
+	return int32(size), nil
+}
+
+// Queries are asked for pageSize+1 rows; the extra row only tells us whether there is a next page.
+func nextPage[T any](items []T, pageSize int32, key func(T) (time.Time, uuid.UUID)) ([]T, *string) {
+	if len(items) <= int(pageSize) {
+		return items, nil
+	}
+
+	items = items[:pageSize]
+	cursor := encodeCursor(key(items[len(items)-1]))
+	return items, &cursor
+}
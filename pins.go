@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/Mielecki/Chirpy/internal/auth"
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerPin(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing chirpID error", err)
+		return
+	}
+
+	chirp, err := cfg.getPublishedChirp(req.Context(), chirpID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No chirp error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Getting chirp error", err)
+		return
+	}
+
+	if chirp.UserID != userID {
+		respondWithError(w, http.StatusForbidden, "You can only pin your own chirps", nil)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Pinning error", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	// Locking the user row serializes concurrent pins, so the limit can't be overshot.
+	user, err := qtx.GetUserByIDForUpdate(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting user error", err)
+		return
+	}
+
+	pinned, err := qtx.ListPinnedChirpIDs(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Pinning error", err)
+		return
+	}
+	if slices.Contains(pinned, chirp.ID) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if len(pinned) >= cfg.entitlements.For(user.IsChirpyRed.Bool).MaxPins {
+		respondWithError(w, http.StatusConflict, "Too many pinned chirps", nil)
+		return
+	}
+
+	if err := qtx.CreatePin(req.Context(), database.CreatePinParams{
+		UserID:  userID,
+		ChirpID: chirp.ID,
+	}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Pinning error", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Pinning error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnpin(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing chirpID error", err)
+		return
+	}
+
+	if err := cfg.database.DeletePin(req.Context(), database.DeletePinParams{
+		UserID:  userID,
+		ChirpID: chirpID,
+	}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Unpinning error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerReorderPins(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		ChirpIDs []uuid.UUID `json:"chirp_ids"`
+	}
+
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	data := parameters{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&data); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Decoding error", err)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Reordering pins error", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	if _, err := qtx.GetUserByIDForUpdate(req.Context(), userID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting user error", err)
+		return
+	}
+
+	pinned, err := qtx.ListPinnedChirpIDs(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Reordering pins error", err)
+		return
+	}
+
+	// The new order has to name every pinned chirp exactly once.
+	ordered := slices.Clone(data.ChirpIDs)
+	slices.SortFunc(ordered, func(a, b uuid.UUID) int { return slices.Compare(a[:], b[:]) })
+	slices.SortFunc(pinned, func(a, b uuid.UUID) int { return slices.Compare(a[:], b[:]) })
+	if !slices.Equal(ordered, pinned) {
+		respondWithError(w, http.StatusBadRequest, "chirp_ids must list each pinned chirp once", nil)
+		return
+	}
+
+	if err := qtx.ReorderPins(req.Context(), database.ReorderPinsParams{
+		ChirpIds: data.ChirpIDs,
+		UserID:   userID,
+	}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Reordering pins error", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Reordering pins error", err)
+		return
+	}
+
+	chirps, err := cfg.database.ListPinnedChirps(req.Context(), database.ListPinnedChirpsParams{
+		UserID:   userID,
+		ViewerID: uuid.NullUUID{UUID: userID, Valid: true},
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting pinned chirps error", err)
+		return
+	}
+
+	chirpsJSON, err := cfg.pinnedChirpsToJSON(req.Context(), uuid.NullUUID{UUID: userID, Valid: true}, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting pinned chirps error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chirpsJSON)
+}
+
+func (cfg *apiConfig) pinnedChirpsToJSON(ctx context.Context, viewerID uuid.NullUUID, chirps []database.Chirp) ([]Chirp, error) {
+	chirpsJSON, err := cfg.chirpsToJSON(ctx, viewerID, chirps)
+	if err != nil {
+		return nil, err
+	}
+	for i := range chirpsJSON {
+		chirpsJSON[i].Pinned = true
+	}
+	return chirpsJSON, nil
+}
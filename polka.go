@@ -1,44 +1,154 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/Mielecki/Chirpy/internal/auth"
+	"github.com/Mielecki/Chirpy/internal/database"
 	"github.com/google/uuid"
 )
 
-func (cfg *apiConfig) handlerPolka(w http.ResponseWriter, req *http.Request) {
-	type parameters struct {
-		Event string `json:"event"`
-		Data  struct {
-			UserID uuid.UUID `json:"user_id"`
-		} `json:"data"`
+const (
+	polkaUserUpgraded          = "user.upgraded"
+	polkaSubscriptionRenewed   = "subscription.renewed"
+	polkaUserDowngraded        = "user.downgraded"
+	polkaSubscriptionCancelled = "subscription.cancelled"
+	polkaPaymentFailed         = "payment.failed"
+)
+
+const (
+	maxWebhookBodySize = 1 << 20
+	// Deliveries replayed within the window are caught by their event id, or for events without
+	// one by their signed timestamp and body.
+	webhookTolerance = 5 * time.Minute
+)
+
+var errUnknownPolkaEvent = errors.New("unknown event type")
+
+type polkaEvent struct {
+	ID    string `json:"id"`
+	Event string `json:"event"`
+	Data  struct {
+		UserID         uuid.UUID  `json:"user_id"`
+		SubscriptionID string     `json:"subscription_id"`
+		Plan           string     `json:"plan"`
+		PeriodStart    *time.Time `json:"period_start"`
+		PeriodEnd      *time.Time `json:"period_end"`
+	} `json:"data"`
+}
+
+func applyPolkaEvent(ctx context.Context, qtx *database.Queries, event polkaEvent) error {
+	userID := event.Data.UserID
+	switch event.Event {
+	case polkaUserUpgraded, polkaSubscriptionRenewed:
+		// Older events carry no period; the subscription then lasts until Polka ends it.
+		periodStart := time.Now()
+		if event.Data.PeriodStart != nil {
+			periodStart = *event.Data.PeriodStart
+		}
+		periodEnd := sql.NullTime{}
+		if event.Data.PeriodEnd != nil {
+			periodEnd = sql.NullTime{Time: event.Data.PeriodEnd.UTC(), Valid: true}
+		}
+		plan := event.Data.Plan
+		if plan == "" {
+			plan = "red"
+		}
+		return activateSubscription(ctx, qtx, database.UpsertSubscriptionParams{
+			UserID:              userID,
+			Plan:                plan,
+			CurrentPeriodStart:  periodStart.UTC(),
+			CurrentPeriodEnd:    periodEnd,
+			PolkaSubscriptionID: sql.NullString{String: event.Data.SubscriptionID, Valid: event.Data.SubscriptionID != ""},
+		})
+	case polkaUserDowngraded:
+		return endSubscription(ctx, qtx, userID)
+	case polkaSubscriptionCancelled:
+		return stopRenewal(ctx, qtx, userID, subscriptionCancelled)
+	case polkaPaymentFailed:
+		return stopRenewal(ctx, qtx, userID, subscriptionPastDue)
+	}
+	return errUnknownPolkaEvent
+}
+
+// verifyPolka checks the request's signature against every active secret. Until secrets are
+// configured the static POLKA_KEY is accepted instead; once they are, it no longer is.
+func (cfg *apiConfig) verifyPolka(req *http.Request, payload []byte) error {
+	if len(cfg.polkaSecrets) > 0 {
+		return auth.VerifyWebhook(req.Header, payload, cfg.polkaSecrets, webhookTolerance, time.Now())
 	}
 
-	if key, err := auth.GetAPIKey(req.Header); err != nil || key != cfg.polkaKey{
-		respondWithError(w, http.StatusUnauthorized, "Invalid Polka key", err)
+	key, err := auth.GetAPIKey(req.Header)
+	if err != nil {
+		return err
+	}
+	if !auth.KeysMatch(key, cfg.polkaKey) {
+		return errors.New("invalid Polka key")
+	}
+	return nil
+}
+
+// Polka retries a webhook until it gets a 2xx, so every delivery is recorded by its event id
+// and a replay of one that already went through is answered without doing anything.
+func (cfg *apiConfig) handlerPolka(w http.ResponseWriter, req *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxWebhookBodySize))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Reading body error", err)
 		return
 	}
 
+	if err := cfg.verifyPolka(req, payload); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid Polka signature", err)
+		return
+	}
 
-	data := parameters{}
-	decoder := json.NewDecoder(req.Body)
-	if err := decoder.Decode(&data); err != nil {
-		respondWithError(w, http.StatusInternalServerError, "Decoding error", err)
+	event := polkaEvent{}
+	// A body that isn't JSON won't get any better on retry.
+	if err := json.Unmarshal(payload, &event); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Decoding error", err)
 		return
 	}
 
-	if data.Event != "user.upgraded" {
-		respondWithError(w, 204, "Unknown event", errors.New("unknown event type"))
+	// Two identical bodies can be two real events, such as a second upgrade after a downgrade, so
+	// without Polka's id a signed event is keyed by its timestamp too: a new event is signed anew,
+	// while a replayed request carries the same timestamp. With only the static key there is no
+	// timestamp to go by, and such events are applied every time.
+	eventID := event.ID
+	if eventID == "" {
+		timestamp := req.Header.Get(auth.WebhookTimestampHeader)
+		if len(cfg.polkaSecrets) > 0 && timestamp != "" {
+			sum := sha256.Sum256(append([]byte(timestamp+"."), payload...))
+			eventID = "sha256:" + hex.EncodeToString(sum[:])
+		} else {
+			eventID = "local:" + uuid.NewString()
+		}
+	}
+
+	if err := cfg.database.RecordWebhookEvent(req.Context(), database.RecordWebhookEventParams{
+		EventID:   eventID,
+		EventType: event.Event,
+		Payload:   payload,
+	}); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Recording event error", err)
 		return
 	}
 
-	if _, err := cfg.database.UpgradeToChripyRed(req.Context(), data.Data.UserID); err != nil {
-		respondWithError(w, 404, "upgrading error", err)
+	if _, err := cfg.processWebhookEvent(req.Context(), eventID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, 404, "No user or subscription", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Handling event error", err)
 		return
 	}
 
 	respondWithJSON(w, 204, struct{}{}) 
-}
\ No newline at end of file
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/auth"
+	"github.com/Mielecki/Chirpy/internal/chirptext"
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 25
+	minPollDuration     = 5 * time.Minute
+	maxPollDuration     = 7 * 24 * time.Hour
+)
+
+type PollParameters struct {
+	Options   []string  `json:"options"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Votes and percentages are left out until the viewer has voted or the poll is closed.
+type Poll struct {
+	ExpiresAt  time.Time    `json:"expires_at"`
+	Closed     bool         `json:"closed"`
+	TotalVotes *int64       `json:"total_votes,omitempty"`
+	MyVote     *uuid.UUID   `json:"my_vote,omitempty"`
+	Options    []PollOption `json:"options"`
+}
+
+type PollOption struct {
+	ID         uuid.UUID `json:"id"`
+	Text       string    `json:"text"`
+	Votes      *int64    `json:"votes,omitempty"`
+	Percentage *float64  `json:"percentage,omitempty"`
+}
+
+// validatePoll cleans up the options in place; opensAt is when the chirp becomes visible.
+func validatePoll(poll *PollParameters, opensAt time.Time) error {
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return errors.New("poll must have 2 to 4 options")
+	}
+
+	options := make([]string, 0, len(poll.Options))
+	for _, option := range poll.Options {
+		option = strings.TrimSpace(option)
+		if option == "" || chirptext.CountGraphemes(option) > maxPollOptionLength {
+			return errors.New("poll options must be 1 to 25 characters long")
+		}
+		if slices.Contains(options, option) {
+			return errors.New("poll options must be unique")
+		}
+		options = append(options, option)
+	}
+	poll.Options = options
+
+	duration := poll.ExpiresAt.Sub(opensAt)
+	if duration < minPollDuration || duration > maxPollDuration {
+		return errors.New("poll must run for 5 minutes to 7 days")
+	}
+	return nil
+}
+
+func savePoll(ctx context.Context, qtx *database.Queries, chirpID uuid.UUID, poll PollParameters) error {
+	if err := qtx.CreatePoll(ctx, database.CreatePollParams{
+		ChirpID:   chirpID,
+		ExpiresAt: poll.ExpiresAt.UTC(),
+	}); err != nil {
+		return err
+	}
+
+	return qtx.CreatePollOptions(ctx, database.CreatePollOptionsParams{
+		ChirpID: chirpID,
+		Texts:   poll.Options,
+	})
+}
+
+func (cfg *apiConfig) pollsByChirpIDs(ctx context.Context, viewerID uuid.NullUUID, chirpIDs []uuid.UUID) (map[uuid.UUID]*Poll, error) {
+	polls := map[uuid.UUID]*Poll{}
+
+	pollRows, err := cfg.database.ListPollsByChirpIDs(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(pollRows) == 0 {
+		return polls, nil
+	}
+
+	pollIDs := make([]uuid.UUID, 0, len(pollRows))
+	for _, row := range pollRows {
+		polls[row.ChirpID] = &Poll{
+			ExpiresAt: row.ExpiresAt,
+			Closed:    row.Closed,
+			Options:   []PollOption{},
+		}
+		pollIDs = append(pollIDs, row.ChirpID)
+	}
+
+	if viewerID.Valid {
+		votes, err := cfg.database.ListPollVotes(ctx, database.ListPollVotesParams{
+			UserID:   viewerID.UUID,
+			ChirpIds: pollIDs,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, vote := range votes {
+			polls[vote.ChirpID].MyVote = &vote.OptionID
+		}
+	}
+
+	optionRows, err := cfg.database.ListPollOptionsByChirpIDs(ctx, pollIDs)
+	if err != nil {
+		return nil, err
+	}
+	totals := map[uuid.UUID]int64{}
+	for _, row := range optionRows {
+		totals[row.ChirpID] += row.Votes
+	}
+
+	for _, row := range optionRows {
+		poll := polls[row.ChirpID]
+		option := PollOption{
+			ID:   row.ID,
+			Text: row.Text,
+		}
+		if poll.MyVote != nil || poll.Closed {
+			total := totals[row.ChirpID]
+			percentage := 0.0
+			if total > 0 {
+				percentage = math.Round(float64(row.Votes)*1000/float64(total)) / 10
+			}
+			option.Votes = &row.Votes
+			option.Percentage = &percentage
+			poll.TotalVotes = &total
+		}
+		poll.Options = append(poll.Options, option)
+	}
+
+	return polls, nil
+}
+
+func (cfg *apiConfig) handlerVote(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		OptionID uuid.UUID `json:"option_id"`
+	}
+
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing chirpID error", err)
+		return
+	}
+
+	data := parameters{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&data); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Decoding error", err)
+		return
+	}
+
+	chirp, err := cfg.getPublishedChirp(req.Context(), chirpID, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No chirp error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Getting chirp error", err)
+		return
+	}
+
+	viewerID := uuid.NullUUID{UUID: userID, Valid: true}
+	polls, err := cfg.pollsByChirpIDs(req.Context(), viewerID, []uuid.UUID{chirp.ID})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting poll error", err)
+		return
+	}
+	poll, ok := polls[chirp.ID]
+	if !ok {
+		respondWithError(w, http.StatusNotFound, "Chirp has no poll", nil)
+		return
+	}
+	if poll.MyVote != nil {
+		respondWithError(w, http.StatusConflict, "You already voted", nil)
+		return
+	}
+	if poll.Closed {
+		respondWithError(w, http.StatusConflict, "Poll is closed", nil)
+		return
+	}
+	if !slices.ContainsFunc(poll.Options, func(option PollOption) bool { return option.ID == data.OptionID }) {
+		respondWithError(w, http.StatusBadRequest, "Invalid poll option", nil)
+		return
+	}
+
+	voted, err := cfg.database.CreatePollVote(req.Context(), database.CreatePollVoteParams{
+		UserID:   userID,
+		OptionID: data.OptionID,
+		ChirpID:  chirp.ID,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "You already voted", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Voting error", err)
+		return
+	}
+	// The poll closed between the check above and the insert.
+	if voted == 0 {
+		respondWithError(w, http.StatusConflict, "Poll is closed", nil)
+		return
+	}
+
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), viewerID, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Voting error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, chirpsJSON[0])
+}
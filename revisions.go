@@ -0,0 +1,58 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ChirpRevision struct {
+	ID        uuid.UUID `json:"id"`
+	ChirpID   uuid.UUID `json:"chirp_id"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (cfg *apiConfig) handlerGetChirpRevisions(w http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing chirpID error", err)
+		return
+	}
+
+	viewerID, err := cfg.optionalUserID(req)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	if _, err := cfg.getVisibleChirp(req.Context(), chirpID, viewerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No chirp error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Getting chirp error", err)
+		return
+	}
+
+	revisions, err := cfg.database.ListChirpRevisions(req.Context(), chirpID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting revisions error", err)
+		return
+	}
+
+	revisionsJSON := []ChirpRevision{}
+	for _, revision := range revisions {
+		revisionsJSON = append(revisionsJSON, ChirpRevision{
+			ID:        revision.ID,
+			ChirpID:   revision.ChirpID,
+			Body:      revision.Body,
+			CreatedAt: revision.CreatedAt,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, revisionsJSON)
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/auth"
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	maxScheduleAhead  = 365 * 24 * time.Hour
+	publishBatchSize  = 100
+	publisherInterval = 10 * time.Second
+)
+
+// publishDueChirps makes every scheduled chirp whose publish_at has passed visible, in batches.
+func (cfg *apiConfig) publishDueChirps(ctx context.Context) error {
+	for {
+		published, err := cfg.publishChirpBatch(ctx)
+		if err != nil {
+			return err
+		}
+		if published < publishBatchSize {
+			return nil
+		}
+	}
+}
+
+func (cfg *apiConfig) publishChirpBatch(ctx context.Context) (int, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	chirps, err := qtx.PublishDueChirps(ctx, publishBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, chirp := range chirps {
+		if err := saveHashtags(ctx, qtx, chirp); err != nil {
+			return 0, err
+		}
+		if err := saveMentions(ctx, qtx, chirp); err != nil {
+			return 0, err
+		}
+		if err := enqueueWebhook(ctx, qtx, eventChirpCreated, webhookChirpFromDatabase(chirp)); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(chirps), tx.Commit()
+}
+
+func (cfg *apiConfig) handlerGetScheduledChirps(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirps, err := cfg.database.ListScheduledChirps(req.Context(), userID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting scheduled chirps error", err)
+		return
+	}
+
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), uuid.NullUUID{UUID: userID, Valid: true}, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting scheduled chirps error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chirpsJSON)
+}
+
+func (cfg *apiConfig) handlerRescheduleChirp(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		PublishAt time.Time `json:"publish_at"`
+	}
+
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing chirpID error", err)
+		return
+	}
+
+	data := parameters{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&data); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Decoding error", err)
+		return
+	}
+
+	if !data.PublishAt.After(time.Now()) || data.PublishAt.After(time.Now().Add(maxScheduleAhead)) {
+		respondWithError(w, http.StatusBadRequest, "publish_at must be in the future and within a year", nil)
+		return
+	}
+
+	chirp, ok := cfg.getScheduledChirp(w, req, chirpID, userID)
+	if !ok {
+		return
+	}
+
+	// Expiry and poll windows count from publishing, so they have to fit the new time as well.
+	if chirp.ExpiresAt.Valid {
+		lifetime := chirp.ExpiresAt.Time.Sub(data.PublishAt)
+		if lifetime < minChirpLifetime || lifetime > maxChirpLifetime {
+			respondWithError(w, http.StatusBadRequest, "Chirp must live for 1 minute to 30 days after publish_at", nil)
+			return
+		}
+	}
+
+	polls, err := cfg.database.ListPollsByChirpIDs(req.Context(), []uuid.UUID{chirp.ID})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting poll error", err)
+		return
+	}
+	for _, poll := range polls {
+		duration := poll.ExpiresAt.Sub(data.PublishAt)
+		if duration < minPollDuration || duration > maxPollDuration {
+			respondWithError(w, http.StatusBadRequest, "Poll must run for 5 minutes to 7 days after publish_at", nil)
+			return
+		}
+	}
+
+	chirp, err = cfg.database.RescheduleChirp(req.Context(), database.RescheduleChirpParams{
+		PublishAt: sql.NullTime{Time: data.PublishAt.UTC(), Valid: true},
+		ID:        chirp.ID,
+	})
+	if err != nil {
+		// The publisher got to it between the lookup and the update.
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusConflict, "Chirp is already published", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Rescheduling error", err)
+		return
+	}
+
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), uuid.NullUUID{UUID: userID, Valid: true}, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Rescheduling error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chirpsJSON[0])
+}
+
+func (cfg *apiConfig) handlerCancelScheduledChirp(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing chirpID error", err)
+		return
+	}
+
+	chirp, ok := cfg.getScheduledChirp(w, req, chirpID, userID)
+	if !ok {
+		return
+	}
+
+	if err := cfg.deleteChirp(req.Context(), chirp); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Canceling chirp error", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteChirp removes a cancelled chirp for good, skipping the trash: it was never published.
+func (cfg *apiConfig) deleteChirp(ctx context.Context, chirp database.Chirp) error {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	attachments, err := purgeChirps(ctx, cfg.database.WithTx(tx), []database.Chirp{chirp})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		cfg.deleteBlobs(ctx, attachment.StorageKey, attachment.ThumbnailKey)
+	}
+	return nil
+}
+
+// getScheduledChirp writes the error response itself and reports whether the handler may continue.
+func (cfg *apiConfig) getScheduledChirp(w http.ResponseWriter, req *http.Request, chirpID, userID uuid.UUID) (database.Chirp, bool) {
+	chirp, err := cfg.getVisibleChirp(req.Context(), chirpID, uuid.NullUUID{UUID: userID, Valid: true})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No chirp error", err)
+			return database.Chirp{}, false
+		}
+		respondWithError(w, http.StatusInternalServerError, "Getting chirp error", err)
+		return database.Chirp{}, false
+	}
+
+	if chirp.UserID != userID {
+		respondWithError(w, http.StatusForbidden, "You are not author of the chirp", nil)
+		return database.Chirp{}, false
+	}
+
+	if !chirp.PublishAt.Valid {
+		respondWithError(w, http.StatusConflict, "Chirp is already published", nil)
+		return database.Chirp{}, false
+	}
+
+	return chirp, true
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/Mielecki/Chirpy/internal/search"
+)
+
+func (cfg *apiConfig) handlerSearchChirps(w http.ResponseWriter, req *http.Request) {
+	query := search.ToTSQuery(req.URL.Query().Get("q"))
+	if query == "" {
+		respondWithError(w, http.StatusBadRequest, "Search query is empty", nil)
+		return
+	}
+
+	authorID, err := parseAuthorFilter(req.URL.Query().Get("author_id"))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Parsing error", err)
+		return
+	}
+
+	viewerID, err := cfg.optionalUserID(req)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	pageSize, err := parsePageSize(req.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	offset, err := decodeOffsetCursor(req.URL.Query().Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	rows, err := cfg.database.SearchChirps(req.Context(), database.SearchChirpsParams{
+		Query:      query,
+		ViewerID:   viewerID,
+		UserID:     authorID,
+		PageSize:   pageSize + 1,
+		PageOffset: offset,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Searching chirps error", err)
+		return
+	}
+
+	var nextCursor *string
+	if len(rows) > int(pageSize) {
+		rows = rows[:pageSize]
+		cursor := encodeOffsetCursor(offset + pageSize)
+		nextCursor = &cursor
+	}
+
+	chirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, row.Chirp)
+	}
+
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), viewerID, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Searching chirps error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ChirpPage{
+		Chirps:     chirpsJSON,
+		NextCursor: nextCursor,
+	})
+}
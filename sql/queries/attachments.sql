@@ -0,0 +1,33 @@
+-- name: CreateAttachment :one
+INSERT INTO attachments (id, created_at, user_id, chirp_id, content_type, size_bytes, width, height, storage_key, thumbnail_key)
+VALUES (
+    $1,
+    NOW(),
+    $2,
+    NULL,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8
+)
+RETURNING *;
+
+-- name: AttachToChirp :execrows
+UPDATE attachments
+SET chirp_id = sqlc.arg('chirp_id')
+WHERE id = ANY(sqlc.arg('ids')::uuid[])
+AND user_id = sqlc.arg('user_id')
+AND chirp_id IS NULL;
+
+-- name: ListAttachmentsByChirpIDs :many
+SELECT *
+FROM attachments
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY created_at, id;
+
+-- name: GetAttachmentByKey :one
+SELECT *
+FROM attachments
+WHERE storage_key = $1 OR thumbnail_key = $1;
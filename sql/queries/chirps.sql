@@ -1,31 +1,204 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, parent_id, root_id, rechirp_of, quote_of, publish_at, expires_at, visibility)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
 )
 RETURNING *;
 
--- name: GetAllChirps :many
+-- name: ListChirpsAsc :many
 SELECT *
 FROM chirps
-ORDER BY created_at;
+WHERE (sqlc.narg('user_id')::uuid IS NULL OR user_id = sqlc.narg('user_id'))
+AND (publish_at IS NULL OR user_id = sqlc.narg('viewer_id'))
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+AND chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id'))
+AND (sqlc.narg('user_id')::uuid IS NULL OR id NOT IN (SELECT chirp_id FROM pinned_chirps WHERE pinned_chirps.user_id = sqlc.narg('user_id')))
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (created_at, id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg('page_size');
 
--- name: GetAllChirpsByUserID :many
+-- name: ListChirpsDesc :many
 SELECT *
 FROM chirps
-WHERE user_id = $1
-ORDER BY created_at;
+WHERE (sqlc.narg('user_id')::uuid IS NULL OR user_id = sqlc.narg('user_id'))
+AND (publish_at IS NULL OR user_id = sqlc.narg('viewer_id'))
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+AND chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id'))
+AND (sqlc.narg('user_id')::uuid IS NULL OR id NOT IN (SELECT chirp_id FROM pinned_chirps WHERE pinned_chirps.user_id = sqlc.narg('user_id')))
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_size');
 
 -- name: GetChirp :one
 SELECT *
 FROM chirps
-WHERE id = $1;
+WHERE id = $1
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: GetChirpForUpdate :one
+SELECT *
+FROM chirps
+WHERE id = $1
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+FOR UPDATE;
+
+-- name: GetVisibleChirp :one
+SELECT *
+FROM chirps
+WHERE id = sqlc.arg('id')
+AND (publish_at IS NULL OR user_id = sqlc.narg('viewer_id'))
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+AND chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id'));
+
+-- name: ListChirpsByIDs :many
+SELECT *
+FROM chirps
+WHERE id = ANY(sqlc.arg('ids')::uuid[])
+AND publish_at IS NULL
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+AND chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id'));
+
+-- name: CountRechirpsByChirpIDs :many
+SELECT rechirp_of, COUNT(*)
+FROM chirps
+WHERE rechirp_of = ANY(sqlc.arg('chirp_ids')::uuid[])
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+GROUP BY rechirp_of;
+
+-- name: ListConversationChirps :many
+SELECT *
+FROM chirps
+WHERE (id = sqlc.arg('root_id') OR root_id = sqlc.arg('root_id'))
+AND publish_at IS NULL
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+AND chirp_visible_to(id, user_id, visibility, sqlc.narg('viewer_id'))
+ORDER BY created_at, id;
+
+-- name: ListConversationLinks :many
+SELECT id, parent_id
+FROM chirps
+WHERE (id = $1 OR root_id = $1)
+AND publish_at IS NULL
+ORDER BY created_at, id;
+
+-- name: ListScheduledChirps :many
+SELECT *
+FROM chirps
+WHERE user_id = $1
+AND publish_at IS NOT NULL
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+ORDER BY publish_at, id;
+
+-- name: RescheduleChirp :one
+UPDATE chirps
+SET publish_at = $1, updated_at = NOW()
+WHERE id = $2
+AND publish_at IS NOT NULL
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING *;
+
+-- name: PublishDueChirps :many
+UPDATE chirps
+SET publish_at = NULL, created_at = NOW(), updated_at = NOW()
+WHERE id IN (
+    SELECT due.id
+    FROM chirps AS due
+    WHERE due.publish_at <= NOW()
+    AND due.deleted_at IS NULL
+    AND (due.expires_at IS NULL OR due.expires_at > NOW())
+    ORDER BY due.publish_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: UpdateChirpBody :one
+UPDATE chirps
+SET body = $1, updated_at = NOW()
+WHERE id = $2
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING *;
 
 -- name: DeleteChirp :exec
 DELETE
 FROM chirps
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: SoftDeleteChirp :exec
+UPDATE chirps
+SET deleted_at = NOW()
+WHERE id = $1
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: RestoreChirp :one
+UPDATE chirps
+SET deleted_at = NULL
+WHERE id = $1
+AND user_id = $2
+AND deleted_at IS NOT NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+RETURNING *;
+
+-- name: ListTrashedChirps :many
+SELECT *
+FROM chirps
+WHERE user_id = sqlc.arg('user_id')
+AND deleted_at IS NOT NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (deleted_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY deleted_at DESC, id DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: ListPurgeableChirps :many
+SELECT *
+FROM chirps
+WHERE deleted_at < NOW() - make_interval(secs => sqlc.arg('retention_seconds')::float8)
+ORDER BY deleted_at
+LIMIT sqlc.arg('batch_size')
+FOR UPDATE SKIP LOCKED;
+
+-- name: ListExpiredChirps :many
+SELECT *
+FROM chirps
+WHERE expires_at <= NOW()
+ORDER BY expires_at
+LIMIT sqlc.arg('batch_size')
+FOR UPDATE SKIP LOCKED;
+
+-- name: DeleteChirpsByIDs :exec
+DELETE
+FROM chirps
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: ListRechirpsOf :many
+SELECT *
+FROM chirps
+WHERE rechirp_of = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: CountChirpsSince :one
+SELECT COUNT(*)
+FROM chirps
+WHERE user_id = sqlc.arg('user_id') AND created_at > sqlc.arg('since');
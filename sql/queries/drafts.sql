@@ -0,0 +1,39 @@
+-- name: CreateDraft :one
+INSERT INTO drafts (id, created_at, updated_at, user_id, body)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2
+)
+RETURNING *;
+
+-- name: GetDraft :one
+SELECT *
+FROM drafts
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetDraftForUpdate :one
+SELECT *
+FROM drafts
+WHERE id = $1 AND user_id = $2
+FOR UPDATE;
+
+-- name: ListDrafts :many
+SELECT *
+FROM drafts
+WHERE user_id = sqlc.arg('user_id')
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: UpdateDraft :one
+UPDATE drafts
+SET body = $1, updated_at = NOW()
+WHERE id = $2 AND user_id = $3
+RETURNING *;
+
+-- name: DeleteDraft :execrows
+DELETE FROM drafts
+WHERE id = $1 AND user_id = $2;
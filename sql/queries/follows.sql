@@ -0,0 +1,45 @@
+-- name: CreateFollow :exec
+INSERT INTO follows (follower_id, followee_id, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteFollow :exec
+DELETE
+FROM follows
+WHERE follower_id = $1
+AND followee_id = $2;
+
+-- name: ListFollowers :many
+SELECT users.id, users.created_at, users.updated_at, users.is_chirpy_red, users.handle, follows.created_at AS followed_at
+FROM follows
+JOIN users ON users.id = follows.follower_id
+WHERE follows.followee_id = sqlc.arg('user_id')
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (follows.created_at, users.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY follows.created_at DESC, users.id DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: ListFollowing :many
+SELECT users.id, users.created_at, users.updated_at, users.is_chirpy_red, users.handle, follows.created_at AS followed_at
+FROM follows
+JOIN users ON users.id = follows.followee_id
+WHERE follows.follower_id = sqlc.arg('user_id')
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (follows.created_at, users.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY follows.created_at DESC, users.id DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: ListTimelineChirps :many
+SELECT chirps.*
+FROM chirps
+JOIN follows ON follows.followee_id = chirps.user_id
+WHERE follows.follower_id = sqlc.arg('follower_id')
+AND chirps.publish_at IS NULL
+AND chirps.deleted_at IS NULL
+AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+AND chirp_visible_to(chirps.id, chirps.user_id, chirps.visibility, sqlc.arg('follower_id'))
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('page_size');
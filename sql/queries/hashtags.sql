@@ -0,0 +1,54 @@
+-- name: CreateHashtags :exec
+INSERT INTO hashtags (id, tag, created_at)
+SELECT gen_random_uuid(), tag, NOW()
+FROM unnest(sqlc.arg('tags')::text[]) AS tag
+ON CONFLICT (tag) DO NOTHING;
+
+-- name: CreateChirpHashtags :exec
+INSERT INTO chirp_hashtags (chirp_id, hashtag_id)
+SELECT sqlc.arg('chirp_id')::uuid, id
+FROM hashtags
+WHERE tag = ANY(sqlc.arg('tags')::text[])
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteChirpHashtags :exec
+DELETE
+FROM chirp_hashtags
+WHERE chirp_id = $1;
+
+-- name: DeleteUnusedHashtags :exec
+DELETE
+FROM hashtags
+WHERE tag = ANY(sqlc.arg('tags')::text[])
+AND NOT EXISTS (
+    SELECT 1
+    FROM chirp_hashtags
+    WHERE chirp_hashtags.hashtag_id = hashtags.id
+);
+
+-- name: ListHashtagChirps :many
+SELECT chirps.*
+FROM chirps
+JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+WHERE hashtags.tag = sqlc.arg('tag')
+AND chirps.publish_at IS NULL
+AND chirps.deleted_at IS NULL
+AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+AND chirp_visible_to(chirps.id, chirps.user_id, chirps.visibility, sqlc.narg('viewer_id'))
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (chirps.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('page_size');
+
+-- name: ListTrendingHashtags :many
+SELECT hashtags.tag, COUNT(*) AS chirp_count
+FROM chirp_hashtags
+JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+JOIN chirps ON chirps.id = chirp_hashtags.chirp_id
+WHERE chirps.deleted_at IS NULL
+AND chirps.visibility = 'public'
+AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+AND chirps.created_at > NOW() - make_interval(secs => sqlc.arg('window_seconds')::float8)
+GROUP BY hashtags.tag
+ORDER BY chirp_count DESC, hashtags.tag
+LIMIT sqlc.arg('page_size');
@@ -0,0 +1,39 @@
+-- name: CreateLike :exec
+INSERT INTO likes (user_id, chirp_id, created_at)
+VALUES (
+    $1,
+    $2,
+    NOW()
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteLike :exec
+DELETE
+FROM likes
+WHERE user_id = $1
+AND chirp_id = $2;
+
+-- name: CountLikesByChirpIDs :many
+SELECT chirp_id, COUNT(*)
+FROM likes
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+GROUP BY chirp_id;
+
+-- name: ListLikedChirpIDs :many
+SELECT chirp_id
+FROM likes
+WHERE user_id = sqlc.arg('user_id')
+AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: ListLikedChirps :many
+SELECT sqlc.embed(chirps), likes.created_at AS liked_at
+FROM likes
+JOIN chirps ON chirps.id = likes.chirp_id
+WHERE likes.user_id = sqlc.arg('user_id')
+AND chirps.publish_at IS NULL
+AND chirps.deleted_at IS NULL
+AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+AND chirp_visible_to(chirps.id, chirps.user_id, chirps.visibility, sqlc.narg('viewer_id'))
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (likes.created_at, chirps.id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY likes.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('page_size');
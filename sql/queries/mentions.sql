@@ -0,0 +1,30 @@
+-- name: CreateMentions :exec
+INSERT INTO mentions (chirp_id, user_id, start_offset, end_offset, created_at)
+SELECT sqlc.arg('chirp_id')::uuid, unnest(sqlc.arg('user_ids')::uuid[]), unnest(sqlc.arg('start_offsets')::integer[]), unnest(sqlc.arg('end_offsets')::integer[]), NOW();
+
+-- name: DeleteChirpMentions :exec
+DELETE
+FROM mentions
+WHERE chirp_id = $1;
+
+-- name: ListMentionsByChirpIDs :many
+SELECT *
+FROM mentions
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+ORDER BY chirp_id, start_offset;
+
+-- name: ListMentioningChirps :many
+SELECT *
+FROM chirps
+WHERE id IN (
+    SELECT chirp_id
+    FROM mentions
+    WHERE mentions.user_id = sqlc.arg('user_id')
+)
+AND publish_at IS NULL
+AND deleted_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+AND chirp_visible_to(id, user_id, visibility, sqlc.arg('user_id'))
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_size');
@@ -0,0 +1,43 @@
+-- name: ListModerationRules :many
+SELECT *
+FROM moderation_rules
+ORDER BY created_at, id;
+
+-- name: CreateModerationRule :one
+INSERT INTO moderation_rules (id, created_at, kind, pattern, action)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+RETURNING *;
+
+-- name: DeleteModerationRule :execrows
+DELETE
+FROM moderation_rules
+WHERE id = $1;
+
+-- name: FlagChirp :exec
+INSERT INTO moderation_flags (chirp_id, created_at, rules)
+VALUES (
+    $1,
+    NOW(),
+    $2
+)
+ON CONFLICT (chirp_id) DO UPDATE
+SET created_at = NOW(), rules = EXCLUDED.rules;
+
+-- name: ListFlaggedChirps :many
+SELECT sqlc.embed(chirps), moderation_flags.rules, moderation_flags.created_at AS flagged_at
+FROM moderation_flags
+JOIN chirps ON chirps.id = moderation_flags.chirp_id
+WHERE (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (moderation_flags.created_at, chirps.id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY moderation_flags.created_at, chirps.id
+LIMIT sqlc.arg('page_size');
+
+-- name: DeleteModerationFlag :execrows
+DELETE
+FROM moderation_flags
+WHERE chirp_id = $1;
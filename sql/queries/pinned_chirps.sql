@@ -0,0 +1,40 @@
+-- name: CreatePin :exec
+INSERT INTO pinned_chirps (user_id, chirp_id, position, created_at)
+SELECT sqlc.arg('user_id')::uuid, sqlc.arg('chirp_id')::uuid, COALESCE(MAX(position) + 1, 0), NOW()
+FROM pinned_chirps
+WHERE user_id = sqlc.arg('user_id')
+ON CONFLICT DO NOTHING;
+
+-- name: DeletePin :exec
+DELETE
+FROM pinned_chirps
+WHERE user_id = $1
+AND chirp_id = $2;
+
+-- name: ListPinnedChirpIDs :many
+SELECT pinned_chirps.chirp_id
+FROM pinned_chirps
+JOIN chirps ON chirps.id = pinned_chirps.chirp_id
+WHERE pinned_chirps.user_id = $1
+AND chirps.publish_at IS NULL
+AND chirps.deleted_at IS NULL
+AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+ORDER BY pinned_chirps.position;
+
+-- name: ListPinnedChirps :many
+SELECT chirps.*
+FROM pinned_chirps
+JOIN chirps ON chirps.id = pinned_chirps.chirp_id
+WHERE pinned_chirps.user_id = sqlc.arg('user_id')
+AND chirps.publish_at IS NULL
+AND chirps.deleted_at IS NULL
+AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+AND chirp_visible_to(chirps.id, chirps.user_id, chirps.visibility, sqlc.narg('viewer_id'))
+ORDER BY pinned_chirps.position;
+
+-- name: ReorderPins :exec
+UPDATE pinned_chirps
+SET position = ordered.position
+FROM unnest(sqlc.arg('chirp_ids')::uuid[]) WITH ORDINALITY AS ordered(chirp_id, position)
+WHERE pinned_chirps.user_id = sqlc.arg('user_id')
+AND pinned_chirps.chirp_id = ordered.chirp_id;
@@ -0,0 +1,39 @@
+-- name: CreatePoll :exec
+INSERT INTO polls (chirp_id, created_at, expires_at)
+VALUES (
+    $1,
+    NOW(),
+    $2
+);
+
+-- name: CreatePollOptions :exec
+INSERT INTO poll_options (id, chirp_id, position, text)
+SELECT gen_random_uuid(), sqlc.arg('chirp_id')::uuid, options.position, options.text
+FROM unnest(sqlc.arg('texts')::text[]) WITH ORDINALITY AS options(text, position);
+
+-- name: ListPollsByChirpIDs :many
+SELECT chirp_id, expires_at, expires_at <= NOW() AS closed
+FROM polls
+WHERE chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: ListPollOptionsByChirpIDs :many
+SELECT poll_options.id, poll_options.chirp_id, poll_options.text, COUNT(poll_votes.user_id) AS votes
+FROM poll_options
+LEFT JOIN poll_votes ON poll_votes.option_id = poll_options.id
+WHERE poll_options.chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[])
+GROUP BY poll_options.id
+ORDER BY poll_options.chirp_id, poll_options.position;
+
+-- name: ListPollVotes :many
+SELECT chirp_id, option_id
+FROM poll_votes
+WHERE user_id = sqlc.arg('user_id') AND chirp_id = ANY(sqlc.arg('chirp_ids')::uuid[]);
+
+-- name: CreatePollVote :execrows
+INSERT INTO poll_votes (chirp_id, user_id, option_id, created_at)
+SELECT poll_options.chirp_id, sqlc.arg('user_id'), poll_options.id, NOW()
+FROM poll_options
+JOIN polls ON polls.chirp_id = poll_options.chirp_id
+WHERE poll_options.id = sqlc.arg('option_id')
+AND poll_options.chirp_id = sqlc.arg('chirp_id')
+AND polls.expires_at > NOW();
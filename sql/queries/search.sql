@@ -0,0 +1,12 @@
+-- name: SearchChirps :many
+SELECT sqlc.embed(chirps), ts_rank(chirps.search_vector, to_tsquery('english', sqlc.arg('query'))) AS rank
+FROM chirps
+WHERE chirps.search_vector @@ to_tsquery('english', sqlc.arg('query'))
+AND chirps.publish_at IS NULL
+AND chirps.deleted_at IS NULL
+AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW())
+AND chirp_visible_to(chirps.id, chirps.user_id, chirps.visibility, sqlc.narg('viewer_id'))
+AND (sqlc.narg('user_id')::uuid IS NULL OR chirps.user_id = sqlc.narg('user_id'))
+ORDER BY rank DESC, chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg('page_size')
+OFFSET sqlc.arg('page_offset');
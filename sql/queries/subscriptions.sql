@@ -0,0 +1,49 @@
+-- name: UpsertSubscription :one
+INSERT INTO subscriptions (user_id, created_at, updated_at, plan, status, current_period_start, current_period_end, polka_subscription_id)
+VALUES (
+    sqlc.arg('user_id'),
+    NOW(),
+    NOW(),
+    sqlc.arg('plan'),
+    'active',
+    sqlc.arg('current_period_start'),
+    sqlc.narg('current_period_end'),
+    sqlc.narg('polka_subscription_id')
+)
+ON CONFLICT (user_id) DO UPDATE
+SET updated_at = NOW(),
+    plan = EXCLUDED.plan,
+    status = 'active',
+    current_period_start = EXCLUDED.current_period_start,
+    current_period_end = EXCLUDED.current_period_end,
+    polka_subscription_id = COALESCE(EXCLUDED.polka_subscription_id, subscriptions.polka_subscription_id)
+RETURNING *;
+
+-- name: GetSubscription :one
+SELECT *
+FROM subscriptions
+WHERE user_id = $1;
+
+-- name: GetSubscriptionForUpdate :one
+SELECT *
+FROM subscriptions
+WHERE user_id = $1
+FOR UPDATE;
+
+-- name: UpdateSubscriptionStatus :execrows
+UPDATE subscriptions
+SET status = sqlc.arg('status'), updated_at = NOW()
+WHERE user_id = sqlc.arg('user_id');
+
+-- name: ExpireSubscriptions :many
+WITH expired AS (
+    UPDATE subscriptions
+    SET status = 'expired', updated_at = NOW()
+    WHERE status <> 'expired' AND current_period_end <= NOW()
+    RETURNING user_id
+)
+UPDATE users
+SET is_chirpy_red = false, updated_at = NOW()
+FROM expired
+WHERE users.id = expired.user_id
+RETURNING users.id;
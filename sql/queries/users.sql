@@ -1,11 +1,12 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, handle)
 VALUES (
     gen_random_uuid(),
     NOW(),
     NOW(),
     $1,
-    $2
+    $2,
+    $3
 )
 RETURNING *;
 
@@ -19,12 +20,28 @@ WHERE email = $1;
 
 -- name: UpdateUser :one
 UPDATE users
-SET email = $1, hashed_password = $2, updated_at = NOW()
-WHERE id = $3
+SET email = sqlc.arg('email'), hashed_password = sqlc.arg('hashed_password'), handle = COALESCE(sqlc.narg('handle'), handle), updated_at = NOW()
+WHERE id = sqlc.arg('id')
 RETURNING *;
 
--- name: UpgradeToChripyRed :one
+-- name: SetChirpyRed :execrows
 UPDATE users
-SET is_chirpy_red = true
+SET is_chirpy_red = sqlc.arg('is_chirpy_red'), updated_at = NOW()
+WHERE id = sqlc.arg('id');
+
+-- name: GetUserByID :one
+SELECT *
+FROM users
+WHERE id = $1;
+
+
+-- name: GetUsersByHandles :many
+SELECT *
+FROM users
+WHERE handle = ANY(sqlc.arg('handles')::text[]);
+
+-- name: GetUserByIDForUpdate :one
+SELECT *
+FROM users
 WHERE id = $1
-RETURNING *;
\ No newline at end of file
+FOR UPDATE;
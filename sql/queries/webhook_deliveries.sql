@@ -0,0 +1,46 @@
+-- name: EnqueueWebhookDeliveries :exec
+INSERT INTO webhook_deliveries (id, endpoint_id, created_at, event_type, payload, status, next_attempt_at)
+SELECT gen_random_uuid(), id, NOW(), sqlc.arg('event_type')::text, sqlc.arg('payload')::jsonb, 'pending', NOW()
+FROM webhook_endpoints
+WHERE enabled
+AND (cardinality(events) = 0 OR sqlc.arg('event_type')::text = ANY(events));
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries
+SET next_attempt_at = sqlc.arg('lease_until')
+WHERE id IN (
+    SELECT webhook_deliveries.id
+    FROM webhook_deliveries
+    JOIN webhook_endpoints ON webhook_endpoints.id = webhook_deliveries.endpoint_id
+    WHERE webhook_deliveries.status = 'pending'
+    AND webhook_deliveries.next_attempt_at <= NOW()
+    AND webhook_endpoints.enabled
+    ORDER BY webhook_deliveries.next_attempt_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE OF webhook_deliveries SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkWebhookDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered', attempts = attempts + 1, last_attempt_at = NOW(), last_status_code = sqlc.arg('last_status_code'), last_error = NULL
+WHERE id = sqlc.arg('id');
+
+-- name: RecordWebhookDeliveryFailure :exec
+UPDATE webhook_deliveries
+SET status = sqlc.arg('status'),
+    attempts = attempts + 1,
+    next_attempt_at = sqlc.arg('next_attempt_at'),
+    last_attempt_at = NOW(),
+    last_status_code = sqlc.narg('last_status_code'),
+    last_error = sqlc.arg('last_error')
+WHERE id = sqlc.arg('id');
+
+-- name: ListWebhookDeliveries :many
+SELECT *
+FROM webhook_deliveries
+WHERE endpoint_id = sqlc.arg('endpoint_id')
+AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (created_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('page_size');
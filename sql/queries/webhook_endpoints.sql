@@ -0,0 +1,60 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (id, created_at, updated_at, url, secret, events)
+VALUES (
+    gen_random_uuid(),
+    NOW(),
+    NOW(),
+    $1,
+    $2,
+    $3
+)
+RETURNING *;
+
+-- name: ListWebhookEndpoints :many
+SELECT *
+FROM webhook_endpoints
+ORDER BY created_at, id;
+
+-- name: ListWebhookEndpointsByIDs :many
+SELECT *
+FROM webhook_endpoints
+WHERE id = ANY(sqlc.arg('ids')::uuid[]);
+
+-- name: GetWebhookEndpoint :one
+SELECT *
+FROM webhook_endpoints
+WHERE id = $1;
+
+-- name: UpdateWebhookEndpoint :one
+UPDATE webhook_endpoints
+SET url = sqlc.arg('url'),
+    events = sqlc.arg('events'),
+    consecutive_failures = CASE WHEN COALESCE(sqlc.narg('enabled')::boolean, enabled) AND NOT enabled THEN 0 ELSE consecutive_failures END,
+    disabled_at = CASE WHEN COALESCE(sqlc.narg('enabled')::boolean, enabled) THEN NULL ELSE COALESCE(disabled_at, NOW()) END,
+    enabled = COALESCE(sqlc.narg('enabled')::boolean, enabled),
+    updated_at = NOW()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: DeleteWebhookEndpoint :execrows
+DELETE FROM webhook_endpoints
+WHERE id = $1;
+
+-- name: RecordWebhookEndpointSuccess :exec
+UPDATE webhook_endpoints
+SET consecutive_failures = 0
+WHERE id = $1 AND consecutive_failures > 0;
+
+-- name: RecordWebhookEndpointFailure :one
+UPDATE webhook_endpoints
+SET consecutive_failures = consecutive_failures + 1,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING consecutive_failures;
+
+-- name: DisableWebhookEndpoint :exec
+UPDATE webhook_endpoints
+SET enabled = FALSE,
+    disabled_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND enabled;
@@ -0,0 +1,35 @@
+-- name: RecordWebhookEvent :exec
+INSERT INTO webhook_events (id, event_id, event_type, payload, received_at, outcome)
+VALUES (
+    gen_random_uuid(),
+    $1,
+    $2,
+    $3,
+    NOW(),
+    'pending'
+)
+ON CONFLICT (event_id) DO NOTHING;
+
+-- name: GetWebhookEvent :one
+SELECT *
+FROM webhook_events
+WHERE id = $1;
+
+-- name: GetWebhookEventForUpdate :one
+SELECT *
+FROM webhook_events
+WHERE event_id = $1
+FOR UPDATE;
+
+-- name: CompleteWebhookEvent :exec
+UPDATE webhook_events
+SET outcome = sqlc.arg('outcome'), error = sqlc.narg('error'), processed_at = NOW(), attempts = attempts + 1
+WHERE id = sqlc.arg('id');
+
+-- name: ListWebhookEvents :many
+SELECT *
+FROM webhook_events
+WHERE (sqlc.narg('outcome')::text IS NULL OR outcome = sqlc.narg('outcome'))
+AND (sqlc.narg('cursor_created_at')::timestamp IS NULL OR (received_at, id) < (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id')::uuid))
+ORDER BY received_at DESC, id DESC
+LIMIT sqlc.arg('page_size');
@@ -0,0 +1,14 @@
+-- +goose Up
+-- No foreign keys here: replies keep pointing at deleted chirps so threads can show a tombstone.
+ALTER TABLE chirps
+ADD COLUMN parent_id UUID,
+ADD COLUMN root_id UUID;
+
+CREATE INDEX chirps_root_id_idx ON chirps (root_id);
+
+-- +goose Down
+DROP INDEX chirps_root_id_idx;
+
+ALTER TABLE chirps
+DROP COLUMN root_id,
+DROP COLUMN parent_id;
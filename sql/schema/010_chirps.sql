@@ -0,0 +1,14 @@
+-- +goose Up
+-- Rechirps go away with the original; quotes keep their own body and just lose the embed.
+ALTER TABLE chirps
+ADD COLUMN rechirp_of UUID REFERENCES chirps(id) ON DELETE CASCADE,
+ADD COLUMN quote_of UUID;
+
+CREATE UNIQUE INDEX chirps_user_id_rechirp_of_idx ON chirps (user_id, rechirp_of) WHERE rechirp_of IS NOT NULL;
+
+-- +goose Down
+DROP INDEX chirps_user_id_rechirp_of_idx;
+
+ALTER TABLE chirps
+DROP COLUMN quote_of,
+DROP COLUMN rechirp_of;
@@ -0,0 +1,34 @@
+-- +goose Up
+ALTER TABLE chirps
+ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public'
+CHECK (visibility IN ('public', 'followers', 'mentioned'));
+
+-- Authors and mentioned users always see a chirp; followers-only chirps are also open to followers.
+-- +goose StatementBegin
+CREATE FUNCTION chirp_visible_to(chirp_id UUID, author_id UUID, visibility TEXT, viewer_id UUID)
+RETURNS BOOLEAN AS $$
+    SELECT COALESCE(
+        visibility = 'public'
+        OR author_id = viewer_id
+        OR (visibility = 'followers' AND EXISTS (
+            SELECT 1
+            FROM follows
+            WHERE follows.follower_id = viewer_id
+            AND follows.followee_id = author_id
+        ))
+        OR EXISTS (
+            SELECT 1
+            FROM mentions
+            WHERE mentions.chirp_id = chirp_visible_to.chirp_id
+            AND mentions.user_id = viewer_id
+        ),
+        FALSE
+    );
+$$ LANGUAGE SQL STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION chirp_visible_to;
+
+ALTER TABLE chirps
+DROP COLUMN visibility;
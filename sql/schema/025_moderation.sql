@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE moderation_rules (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('word', 'regex')),
+    pattern TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('mask', 'flag', 'reject')),
+    UNIQUE (kind, pattern)
+);
+
+INSERT INTO moderation_rules (id, created_at, kind, pattern, action)
+VALUES
+    (gen_random_uuid(), NOW(), 'word', 'kerfuffle', 'mask'),
+    (gen_random_uuid(), NOW(), 'word', 'sharbert', 'mask'),
+    (gen_random_uuid(), NOW(), 'word', 'fornax', 'mask');
+
+CREATE TABLE moderation_flags (
+    chirp_id UUID PRIMARY KEY REFERENCES chirps(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    rules TEXT[] NOT NULL
+);
+
+CREATE INDEX moderation_flags_created_at_idx ON moderation_flags (created_at, chirp_id);
+
+-- +goose Down
+DROP TABLE moderation_flags;
+DROP TABLE moderation_rules;
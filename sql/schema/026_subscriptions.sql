@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE subscriptions (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    plan TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('active', 'cancelled', 'past_due', 'expired')),
+    current_period_start TIMESTAMP NOT NULL,
+    -- NULL when Polka didn't say when the period ends; such subscriptions only end by an event.
+    current_period_end TIMESTAMP,
+    polka_subscription_id TEXT
+);
+
+CREATE INDEX subscriptions_current_period_end_idx ON subscriptions (current_period_end) WHERE status <> 'expired';
+
+-- Upgrades so far carried no period, so existing Red users keep it until Polka tells us otherwise.
+INSERT INTO subscriptions (user_id, created_at, updated_at, plan, status, current_period_start)
+SELECT id, NOW(), NOW(), 'red', 'active', NOW()
+FROM users
+WHERE is_chirpy_red;
+
+-- +goose Down
+DROP TABLE subscriptions;
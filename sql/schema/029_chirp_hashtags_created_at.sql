@@ -0,0 +1,17 @@
+-- +goose Up
+-- Trending counts chirps by when they were posted, so the link's own timestamp is unused.
+DROP INDEX chirp_hashtags_created_at_idx;
+DROP INDEX chirp_hashtags_hashtag_id_created_at_idx;
+ALTER TABLE chirp_hashtags DROP COLUMN created_at;
+CREATE INDEX chirp_hashtags_hashtag_id_idx ON chirp_hashtags (hashtag_id);
+
+-- +goose Down
+DROP INDEX chirp_hashtags_hashtag_id_idx;
+ALTER TABLE chirp_hashtags ADD COLUMN created_at TIMESTAMP;
+UPDATE chirp_hashtags
+SET created_at = chirps.created_at
+FROM chirps
+WHERE chirps.id = chirp_hashtags.chirp_id;
+ALTER TABLE chirp_hashtags ALTER COLUMN created_at SET NOT NULL;
+CREATE INDEX chirp_hashtags_hashtag_id_created_at_idx ON chirp_hashtags (hashtag_id, created_at);
+CREATE INDEX chirp_hashtags_created_at_idx ON chirp_hashtags (created_at);
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/auth"
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	subscriptionActive    = "active"
+	subscriptionCancelled = "cancelled"
+	subscriptionPastDue   = "past_due"
+	subscriptionExpired   = "expired"
+)
+
+const subscriptionSweeperInterval = 5 * time.Minute
+
+// Cancelled and past due subscriptions keep Red until the end of the period that was paid for.
+type Subscription struct {
+	Plan               string     `json:"plan"`
+	Status             string     `json:"status"`
+	CurrentPeriodStart time.Time  `json:"current_period_start"`
+	CurrentPeriodEnd   *time.Time `json:"current_period_end"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+func subscriptionFromDatabase(subscription database.Subscription) Subscription {
+	subscriptionJSON := Subscription{
+		Plan:               subscription.Plan,
+		Status:             subscription.Status,
+		CurrentPeriodStart: subscription.CurrentPeriodStart,
+		CreatedAt:          subscription.CreatedAt,
+		UpdatedAt:          subscription.UpdatedAt,
+	}
+	if subscription.CurrentPeriodEnd.Valid {
+		subscriptionJSON.CurrentPeriodEnd = &subscription.CurrentPeriodEnd.Time
+	}
+	return subscriptionJSON
+}
+
+func setChirpyRed(ctx context.Context, qtx *database.Queries, userID uuid.UUID, chirpyRed bool) error {
+	updated, err := qtx.SetChirpyRed(ctx, database.SetChirpyRedParams{
+		IsChirpyRed: sql.NullBool{Bool: chirpyRed, Valid: true},
+		ID:          userID,
+	})
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// activateSubscription grants Red for a new or renewed period.
+func activateSubscription(ctx context.Context, qtx *database.Queries, params database.UpsertSubscriptionParams) error {
+	if err := setChirpyRed(ctx, qtx, params.UserID, true); err != nil {
+		return err
+	}
+	if _, err := qtx.UpsertSubscription(ctx, params); err != nil {
+		return err
+	}
+	return notifySubscriptionChange(ctx, qtx, params.UserID)
+}
+
+// endSubscription revokes Red right away.
+func endSubscription(ctx context.Context, qtx *database.Queries, userID uuid.UUID) error {
+	if err := setChirpyRed(ctx, qtx, userID, false); err != nil {
+		return err
+	}
+	// Users who never had a subscription have nothing to end.
+	if _, err := qtx.UpdateSubscriptionStatus(ctx, database.UpdateSubscriptionStatusParams{
+		Status: subscriptionExpired,
+		UserID: userID,
+	}); err != nil {
+		return err
+	}
+	return notifySubscriptionChange(ctx, qtx, userID)
+}
+
+// stopRenewal marks a subscription that won't renew. Without a known period end there's
+// nothing left to run out, so it ends right away.
+func stopRenewal(ctx context.Context, qtx *database.Queries, userID uuid.UUID, status string) error {
+	subscription, err := qtx.GetSubscriptionForUpdate(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if subscription.Status == subscriptionExpired {
+		return nil
+	}
+	if !subscription.CurrentPeriodEnd.Valid {
+		return endSubscription(ctx, qtx, userID)
+	}
+
+	if _, err := qtx.UpdateSubscriptionStatus(ctx, database.UpdateSubscriptionStatusParams{
+		Status: status,
+		UserID: userID,
+	}); err != nil {
+		return err
+	}
+	return notifySubscriptionChange(ctx, qtx, userID)
+}
+
+// notifySubscriptionChange queues a webhook with the user's subscription as it now stands.
+func notifySubscriptionChange(ctx context.Context, qtx *database.Queries, userID uuid.UUID) error {
+	subscription, err := qtx.GetSubscription(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return enqueueWebhook(ctx, qtx, eventSubscriptionUpdated, struct {
+		UserID       uuid.UUID    `json:"user_id"`
+		Subscription Subscription `json:"subscription"`
+	}{
+		UserID:       userID,
+		Subscription: subscriptionFromDatabase(subscription),
+	})
+}
+
+// sweepExpiredSubscriptions revokes Red from every subscription whose period has ended.
+// An active subscription whose renewal never arrived runs out the same way.
+func (cfg *apiConfig) sweepExpiredSubscriptions(ctx context.Context) error {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	userIDs, err := qtx.ExpireSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		if err := notifySubscriptionChange(ctx, qtx, userID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (cfg *apiConfig) handlerGetSubscription(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	subscription, err := cfg.database.GetSubscription(req.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No subscription", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Getting subscription error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, subscriptionFromDatabase(subscription))
+}
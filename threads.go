@@ -0,0 +1,120 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// Deleted or unreadable chirps still referenced by replies are rendered as a tombstone: only the ID with deleted set.
+type ThreadChirp struct {
+	ID uuid.UUID `json:"id"`
+	*Chirp
+	Deleted bool          `json:"deleted"`
+	Replies []ThreadChirp `json:"replies,omitempty"`
+}
+
+type Thread struct {
+	Ancestors []ThreadChirp `json:"ancestors"`
+	Chirp     ThreadChirp   `json:"chirp"`
+}
+
+func (cfg *apiConfig) handlerGetThread(w http.ResponseWriter, req *http.Request) {
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing chirpID error", err)
+		return
+	}
+
+	viewerID, err := cfg.optionalUserID(req)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirp, err := cfg.getVisibleChirp(req.Context(), chirpID, viewerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No chirp error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Getting chirp error", err)
+		return
+	}
+
+	rootID := chirp.RootID.UUID
+	if !chirp.RootID.Valid {
+		rootID = chirp.ID
+	}
+
+	conversation, err := cfg.database.ListConversationChirps(req.Context(), database.ListConversationChirpsParams{
+		RootID:   rootID,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting thread error", err)
+		return
+	}
+
+	// Only the author gets this far for a scheduled chirp, which the conversation query leaves out.
+	if chirp.PublishAt.Valid {
+		conversation = append(conversation, chirp)
+	}
+
+	conversationJSON, err := cfg.chirpsToJSON(req.Context(), viewerID, conversation)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting thread error", err)
+		return
+	}
+
+	// Links cover trashed, expired and restricted chirps too, so the walk can pass through them as tombstones.
+	links, err := cfg.database.ListConversationLinks(req.Context(), rootID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting thread error", err)
+		return
+	}
+
+	byID := map[uuid.UUID]Chirp{}
+	for _, c := range conversationJSON {
+		byID[c.ID] = c
+	}
+	parents := map[uuid.UUID]uuid.NullUUID{}
+	replies := map[uuid.UUID][]uuid.UUID{}
+	for _, link := range links {
+		parents[link.ID] = link.ParentID
+		if link.ParentID.Valid {
+			replies[link.ParentID.UUID] = append(replies[link.ParentID.UUID], link.ID)
+		}
+	}
+
+	// A purged parent leaves no link behind, so the walk ends at its tombstone.
+	ancestors := []ThreadChirp{}
+	for parentID := chirp.ParentID; parentID.Valid; parentID = parents[parentID.UUID] {
+		ancestors = append([]ThreadChirp{threadChirp(parentID.UUID, byID, nil)}, ancestors...)
+	}
+
+	respondWithJSON(w, http.StatusOK, Thread{
+		Ancestors: ancestors,
+		Chirp:     threadChirp(chirp.ID, byID, replies),
+	})
+}
+
+// Tombstones are kept only when a readable chirp hangs below them.
+func threadChirp(id uuid.UUID, byID map[uuid.UUID]Chirp, replies map[uuid.UUID][]uuid.UUID) ThreadChirp {
+	node := ThreadChirp{ID: id, Deleted: true}
+	if chirp, ok := byID[id]; ok {
+		node.Chirp = &chirp
+		node.Deleted = false
+	}
+	for _, replyID := range replies[id] {
+		reply := threadChirp(replyID, byID, replies)
+		if reply.Deleted && len(reply.Replies) == 0 {
+			continue
+		}
+		node.Replies = append(node.Replies, reply)
+	}
+	return node
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/auth"
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	purgeBatchSize = 100
+	purgerInterval = time.Hour
+)
+
+func (cfg *apiConfig) handlerGetTrash(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	pageSize, err := parsePageSize(req.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorDeletedAt, cursorID, err := decodeCursor(req.URL.Query().Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	chirps, err := cfg.database.ListTrashedChirps(req.Context(), database.ListTrashedChirpsParams{
+		UserID:          userID,
+		CursorCreatedAt: cursorDeletedAt,
+		CursorID:        cursorID,
+		PageSize:        pageSize + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting trash error", err)
+		return
+	}
+
+	chirps, nextCursor := nextPage(chirps, pageSize, func(chirp database.Chirp) (time.Time, uuid.UUID) {
+		return chirp.DeletedAt.Time, chirp.ID
+	})
+
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), uuid.NullUUID{UUID: userID, Valid: true}, chirps)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting trash error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, ChirpPage{
+		Chirps:     chirpsJSON,
+		NextCursor: nextCursor,
+	})
+}
+
+func (cfg *apiConfig) handlerRestoreChirp(w http.ResponseWriter, req *http.Request) {
+	token, err := auth.GetBearerToken(req.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't find token", err)
+		return
+	}
+
+	userID, err := auth.ValidateJWT(token, cfg.secret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Couldn't validate token", err)
+		return
+	}
+
+	chirpID, err := uuid.Parse(req.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing chirpID error", err)
+		return
+	}
+
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Restoring chirp error", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	chirp, err := qtx.RestoreChirp(req.Context(), database.RestoreChirpParams{
+		ID:     chirpID,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No chirp in trash", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Restoring chirp error", err)
+		return
+	}
+
+	if err := enqueueWebhook(req.Context(), qtx, eventChirpRestored, webhookChirpFromDatabase(chirp)); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Queueing webhooks error", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Restoring chirp error", err)
+		return
+	}
+
+	chirpsJSON, err := cfg.chirpsToJSON(req.Context(), uuid.NullUUID{UUID: userID, Valid: true}, []database.Chirp{chirp})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Restoring chirp error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chirpsJSON[0])
+}
+
+// purgeTrash hard-deletes chirps that have been in the trash longer than the retention period.
+func (cfg *apiConfig) purgeTrash(ctx context.Context) error {
+	return cfg.purgeInBatches(ctx, func(ctx context.Context, qtx *database.Queries) ([]database.Chirp, error) {
+		return qtx.ListPurgeableChirps(ctx, database.ListPurgeableChirpsParams{
+			RetentionSeconds: cfg.trashRetention.Seconds(),
+			BatchSize:        purgeBatchSize,
+		})
+	})
+}
+
+// purgeInBatches hard-deletes whatever list locks, one transaction per batch, until a batch comes back short.
+func (cfg *apiConfig) purgeInBatches(ctx context.Context, list func(context.Context, *database.Queries) ([]database.Chirp, error)) error {
+	for {
+		purged, err := cfg.purgeBatch(ctx, list)
+		if err != nil {
+			return err
+		}
+		if purged < purgeBatchSize {
+			return nil
+		}
+	}
+}
+
+func (cfg *apiConfig) purgeBatch(ctx context.Context, list func(context.Context, *database.Queries) ([]database.Chirp, error)) (int, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	chirps, err := list(ctx, qtx)
+	if err != nil {
+		return 0, err
+	}
+	if len(chirps) == 0 {
+		return 0, nil
+	}
+
+	attachments, err := purgeChirps(ctx, qtx, chirps)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	for _, attachment := range attachments {
+		cfg.deleteBlobs(ctx, attachment.StorageKey, attachment.ThumbnailKey)
+	}
+	return len(chirps), nil
+}
@@ -1,11 +1,13 @@
 package main
 
 import (
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/Mielecki/Chirpy/internal/auth"
+	"github.com/Mielecki/Chirpy/internal/chirptext"
 	"github.com/Mielecki/Chirpy/internal/database"
 	"github.com/google/uuid"
 )
@@ -14,14 +16,16 @@ type User struct {
 	ID        uuid.UUID `json:"id"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
-	Email     string `json:"email"`
+	Email     string `json:"email,omitempty"`
 	IsChirpyRed bool `json:"is_chirpy_red"`
+	Handle string `json:"handle,omitempty"`
 }
 
 func (cfg *apiConfig) handlerUsers(w http.ResponseWriter, req *http.Request) {
 	type parameters struct {
 		Password string `json:"password"`
 		Email string `json:"email"`
+		Handle string `json:"handle"`
 	}
 
 	decoder := json.NewDecoder(req.Body)
@@ -32,27 +36,59 @@ func (cfg *apiConfig) handlerUsers(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	handle, ok := parseHandle(data.Handle)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid handle", nil)
+		return
+	}
+
 	hashedPassword, err := auth.HashPassword(data.Password)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Hashing password error", err)
 	} 
 
-	userData, err := cfg.database.CreateUser(req.Context(), database.CreateUserParams{
+	tx, err := cfg.db.BeginTx(req.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Creating user error", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	userData, err := qtx.CreateUser(req.Context(), database.CreateUserParams{
 		Email: data.Email,
 		HashedPassword: hashedPassword,
+		Handle: handle,
 	})
 	if err != nil {
+		if isUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "Email or handle already taken", err)
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "Creating user error", err)
 		return
 	}
-	
-	respondWithJSON(w, 201, User{
+
+	user := User{
 		ID: userData.ID,
 		CreatedAt: userData.CreatedAt,
 		UpdatedAt: userData.UpdatedAt,
 		Email: userData.Email,
 		IsChirpyRed: userData.IsChirpyRed.Bool,
-	})
+		Handle: userData.Handle.String,
+	}
+
+	if err := enqueueWebhook(req.Context(), qtx, eventUserCreated, user); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Queueing webhooks error", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Creating user error", err)
+		return
+	}
+	
+	respondWithJSON(w, 201, user)
 }
 
 func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, req *http.Request) {
@@ -113,6 +149,7 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, req *http.Request) {
 			UpdatedAt: user.UpdatedAt,
 			Email: user.Email,
 			IsChirpyRed: user.IsChirpyRed.Bool,
+			Handle: user.Handle.String,
 		},
 		Token: token,
 		RefreshToken: refreshToken,
@@ -169,6 +206,7 @@ func (cfg *apiConfig) handlerUpdateUser (w http.ResponseWriter, req *http.Reques
 	type parameters struct {
 		Password string `json:"password"`
 		Email string `json:"email"`
+		Handle string `json:"handle"`
 	}
 
 
@@ -191,6 +229,12 @@ func (cfg *apiConfig) handlerUpdateUser (w http.ResponseWriter, req *http.Reques
 		return
 	}
 
+	handle, ok := parseHandle(data.Handle)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid handle", nil)
+		return
+	}
+
 	hashedPassword, err := auth.HashPassword(data.Password)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Hashing password error", err)
@@ -199,9 +243,14 @@ func (cfg *apiConfig) handlerUpdateUser (w http.ResponseWriter, req *http.Reques
 	user, err := cfg.database.UpdateUser(req.Context(), database.UpdateUserParams{
 		Email: data.Email,
 		HashedPassword: hashedPassword,
+		Handle: handle,
 		ID: userID,
 	})
 	if err != nil {
+		if isUniqueViolation(err) {
+			respondWithError(w, http.StatusConflict, "Email or handle already taken", err)
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "Updating error", err)
 	}
 
@@ -211,5 +260,17 @@ func (cfg *apiConfig) handlerUpdateUser (w http.ResponseWriter, req *http.Reques
 		UpdatedAt: user.UpdatedAt,
 		Email: user.Email,
 		IsChirpyRed: user.IsChirpyRed.Bool,
+		Handle: user.Handle.String,
 	})
-}
\ No newline at end of file
+}
+
+// An empty handle means "not set", which keeps the current handle on update.
+func parseHandle(handle string) (sql.NullString, bool) {
+	if handle == "" {
+		return sql.NullString{}, true
+	}
+	if !chirptext.ValidHandle(handle) {
+		return sql.NullString{}, false
+	}
+	return sql.NullString{String: chirptext.NormalizeHandle(handle), Valid: true}, true
+}
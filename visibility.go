@@ -0,0 +1,20 @@
+package main
+
+import "errors"
+
+// Who may read a chirp besides its author and the users it mentions; enforced by chirp_visible_to in SQL.
+const (
+	visibilityPublic    = "public"
+	visibilityFollowers = "followers"
+	visibilityMentioned = "mentioned"
+)
+
+func parseVisibility(visibility string) (string, error) {
+	switch visibility {
+	case "":
+		return visibilityPublic, nil
+	case visibilityPublic, visibilityFollowers, visibilityMentioned:
+		return visibility, nil
+	}
+	return "", errors.New("visibility must be public, followers or mentioned")
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/auth"
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/Mielecki/Chirpy/internal/webhooks"
+	"github.com/google/uuid"
+)
+
+const (
+	eventChirpCreated        = "chirp.created"
+	eventChirpDeleted        = "chirp.deleted"
+	eventChirpRestored       = "chirp.restored"
+	eventUserCreated         = "user.created"
+	eventSubscriptionUpdated = "subscription.updated"
+)
+
+var webhookEventTypes = []string{eventChirpCreated, eventChirpDeleted, eventChirpRestored, eventUserCreated, eventSubscriptionUpdated}
+
+const (
+	deliveryPending   = "pending"
+	deliveryDelivered = "delivered"
+	deliveryFailed    = "failed"
+)
+
+const (
+	delivererInterval = 5 * time.Second
+	deliveryBatchSize = 20
+	deliveryTimeout   = 10 * time.Second
+	// A claimed delivery is left alone for this long, so it must outlast the request.
+	deliveryLease = time.Minute
+)
+
+// The id is the same for every endpoint an event goes to, so receivers can spot retries.
+type webhookPayload struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// webhookChirp is a chirp as endpoints see it. Endpoints aren't part of the audience of a
+// followers-only or mentioned chirp, so those go out without their body.
+type webhookChirp struct {
+	Chirp
+	Body *string `json:"body,omitempty"`
+}
+
+func webhookChirpFromDatabase(chirp database.Chirp) webhookChirp {
+	data := webhookChirp{Chirp: chirpFromDatabase(chirp)}
+	if chirp.Visibility == visibilityPublic {
+		data.Body = &chirp.Body
+	}
+	return data
+}
+
+// enqueueWebhook queues an event for every endpoint subscribed to it. Called with the
+// transaction that makes the change, so an event is sent exactly when the change sticks.
+func enqueueWebhook(ctx context.Context, qtx *database.Queries, eventType string, data any) error {
+	payload, err := json.Marshal(webhookPayload{
+		ID:        uuid.New(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	return qtx.EnqueueWebhookDeliveries(ctx, database.EnqueueWebhookDeliveriesParams{
+		EventType: eventType,
+		Payload:   payload,
+	})
+}
+
+// enqueueChirpDeleted announces a chirp leaving the timeline: when it's trashed, and again when it's
+// purged for good, since it can no longer be restored. A scheduled chirp was never announced, so
+// cancelling it isn't either.
+func enqueueChirpDeleted(ctx context.Context, qtx *database.Queries, chirp database.Chirp) error {
+	if chirp.PublishAt.Valid {
+		return nil
+	}
+	if !chirp.DeletedAt.Valid {
+		chirp.DeletedAt = sql.NullTime{Time: time.Now().UTC(), Valid: true}
+	}
+	return enqueueWebhook(ctx, qtx, eventChirpDeleted, webhookChirpFromDatabase(chirp))
+}
+
+// deliverWebhooks sends due deliveries until none are left. Claiming takes a lease instead of
+// holding locks during the requests, so several instances can share the queue.
+func (cfg *apiConfig) deliverWebhooks(ctx context.Context) error {
+	for {
+		deliveries, err := cfg.database.ClaimWebhookDeliveries(ctx, database.ClaimWebhookDeliveriesParams{
+			LeaseUntil: time.Now().Add(deliveryLease).UTC(),
+			BatchSize:  deliveryBatchSize,
+		})
+		if err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		endpointIDs := []uuid.UUID{}
+		for _, delivery := range deliveries {
+			endpointIDs = append(endpointIDs, delivery.EndpointID)
+		}
+		endpoints, err := cfg.database.ListWebhookEndpointsByIDs(ctx, endpointIDs)
+		if err != nil {
+			return err
+		}
+		endpointsByID := map[uuid.UUID]database.WebhookEndpoint{}
+		for _, endpoint := range endpoints {
+			endpointsByID[endpoint.ID] = endpoint
+		}
+
+		wg := sync.WaitGroup{}
+		for _, delivery := range deliveries {
+			endpoint, ok := endpointsByID[delivery.EndpointID]
+			if !ok {
+				// Deleted since the claim; its deliveries went with it.
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := cfg.attemptDelivery(ctx, endpoint, delivery); err != nil {
+					log.Printf("webhook delivery %s: %s", delivery.ID, err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if len(deliveries) < deliveryBatchSize {
+			return nil
+		}
+	}
+}
+
+// attemptDelivery sends a delivery once and records the outcome on it and on its endpoint.
+func (cfg *apiConfig) attemptDelivery(ctx context.Context, endpoint database.WebhookEndpoint, delivery database.WebhookDelivery) error {
+	statusCode, sendErr := cfg.sendWebhook(ctx, endpoint, delivery.Payload)
+	lastStatusCode := sql.NullInt32{Int32: int32(statusCode), Valid: statusCode != 0}
+
+	if sendErr == nil {
+		if err := cfg.database.MarkWebhookDelivered(ctx, database.MarkWebhookDeliveredParams{
+			LastStatusCode: lastStatusCode,
+			ID:             delivery.ID,
+		}); err != nil {
+			return err
+		}
+		return cfg.database.RecordWebhookEndpointSuccess(ctx, endpoint.ID)
+	}
+
+	attempts := delivery.Attempts + 1
+	status := deliveryPending
+	if webhooks.Exhausted(attempts) {
+		status = deliveryFailed
+	}
+	if err := cfg.database.RecordWebhookDeliveryFailure(ctx, database.RecordWebhookDeliveryFailureParams{
+		Status:         status,
+		NextAttemptAt:  time.Now().Add(webhooks.Backoff(attempts)).UTC(),
+		LastStatusCode: lastStatusCode,
+		LastError:      sql.NullString{String: sendErr.Error(), Valid: true},
+		ID:             delivery.ID,
+	}); err != nil {
+		return err
+	}
+
+	failures, err := cfg.database.RecordWebhookEndpointFailure(ctx, endpoint.ID)
+	if err != nil {
+		return err
+	}
+	if webhooks.DisableEndpoint(failures) {
+		return cfg.database.DisableWebhookEndpoint(ctx, endpoint.ID)
+	}
+	return nil
+}
+
+// sendWebhook posts a payload signed like the webhooks Chirpy receives: an HMAC of the
+// timestamp and the body under the endpoint's secret.
+func (cfg *apiConfig) sendWebhook(ctx context.Context, endpoint database.WebhookEndpoint, payload []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(auth.WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(auth.WebhookSignatureHeader, "v1="+auth.SignWebhook(endpoint.Secret, timestamp, payload))
+
+	resp, err := cfg.webhookClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
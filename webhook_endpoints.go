@@ -0,0 +1,322 @@
+package main
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// The secret is only shown when the endpoint is created.
+type WebhookEndpoint struct {
+	ID                  uuid.UUID  `json:"id"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	URL                 string     `json:"url"`
+	Secret              string     `json:"secret,omitempty"`
+	Events              []string   `json:"events"`
+	Enabled             bool       `json:"enabled"`
+	ConsecutiveFailures int32      `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at"`
+	LastStatusCode *int32          `json:"last_status_code"`
+	LastError      *string         `json:"last_error"`
+}
+
+type WebhookDeliveryPage struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+	NextCursor *string           `json:"next_cursor"`
+}
+
+func webhookEndpointFromDatabase(endpoint database.WebhookEndpoint) WebhookEndpoint {
+	endpointJSON := WebhookEndpoint{
+		ID:                  endpoint.ID,
+		CreatedAt:           endpoint.CreatedAt,
+		UpdatedAt:           endpoint.UpdatedAt,
+		URL:                 endpoint.Url,
+		Events:              endpoint.Events,
+		Enabled:             endpoint.Enabled,
+		ConsecutiveFailures: endpoint.ConsecutiveFailures,
+	}
+	if endpointJSON.Events == nil {
+		endpointJSON.Events = []string{}
+	}
+	if endpoint.DisabledAt.Valid {
+		endpointJSON.DisabledAt = &endpoint.DisabledAt.Time
+	}
+	return endpointJSON
+}
+
+func webhookDeliveryFromDatabase(delivery database.WebhookDelivery) WebhookDelivery {
+	deliveryJSON := WebhookDelivery{
+		ID:        delivery.ID,
+		CreatedAt: delivery.CreatedAt,
+		EventType: delivery.EventType,
+		Payload:   delivery.Payload,
+		Status:    delivery.Status,
+		Attempts:  delivery.Attempts,
+	}
+	if delivery.Status == deliveryPending {
+		deliveryJSON.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	if delivery.LastAttemptAt.Valid {
+		deliveryJSON.LastAttemptAt = &delivery.LastAttemptAt.Time
+	}
+	if delivery.LastStatusCode.Valid {
+		deliveryJSON.LastStatusCode = &delivery.LastStatusCode.Int32
+	}
+	if delivery.LastError.Valid {
+		deliveryJSON.LastError = &delivery.LastError.String
+	}
+	return deliveryJSON
+}
+
+// validateWebhookEndpoint checks the URL and event filter; no events means all of them.
+func validateWebhookEndpoint(rawURL string, events []string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	for _, event := range events {
+		if !slices.Contains(webhookEventTypes, event) {
+			return errors.New("unknown event " + event)
+		}
+	}
+	return nil
+}
+
+func (cfg *apiConfig) handlerCreateWebhookEndpoint(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		URL    string   `json:"url"`
+		Events []string `json:"events"`
+	}
+
+	if err := cfg.checkAdminKey(req); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid admin key", err)
+		return
+	}
+
+	data := parameters{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&data); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Decoding error", err)
+		return
+	}
+	if data.Events == nil {
+		data.Events = []string{}
+	}
+
+	if err := validateWebhookEndpoint(data.URL, data.Events); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid endpoint: "+err.Error(), err)
+		return
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Generating secret error", err)
+		return
+	}
+
+	endpoint, err := cfg.database.CreateWebhookEndpoint(req.Context(), database.CreateWebhookEndpointParams{
+		Url:    data.URL,
+		Secret: hex.EncodeToString(secret),
+		Events: data.Events,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Creating endpoint error", err)
+		return
+	}
+
+	endpointJSON := webhookEndpointFromDatabase(endpoint)
+	endpointJSON.Secret = endpoint.Secret
+	respondWithJSON(w, http.StatusCreated, endpointJSON)
+}
+
+func (cfg *apiConfig) handlerGetWebhookEndpoints(w http.ResponseWriter, req *http.Request) {
+	if err := cfg.checkAdminKey(req); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid admin key", err)
+		return
+	}
+
+	endpoints, err := cfg.database.ListWebhookEndpoints(req.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting endpoints error", err)
+		return
+	}
+
+	endpointsJSON := []WebhookEndpoint{}
+	for _, endpoint := range endpoints {
+		endpointsJSON = append(endpointsJSON, webhookEndpointFromDatabase(endpoint))
+	}
+
+	respondWithJSON(w, http.StatusOK, endpointsJSON)
+}
+
+// handlerUpdateWebhookEndpoint replaces the URL and event filter, and leaves the endpoint
+// enabled or disabled as it is unless enabled is sent. Re-enabling an endpoint
+// resets its failure count and resumes the deliveries that were pending when it was disabled;
+// events that happened while it was off were never queued for it.
+func (cfg *apiConfig) handlerUpdateWebhookEndpoint(w http.ResponseWriter, req *http.Request) {
+	type parameters struct {
+		URL     string   `json:"url"`
+		Events  []string `json:"events"`
+		Enabled *bool    `json:"enabled"`
+	}
+
+	if err := cfg.checkAdminKey(req); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid admin key", err)
+		return
+	}
+
+	endpointID, err := uuid.Parse(req.PathValue("endpointID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing endpointID error", err)
+		return
+	}
+
+	data := parameters{}
+	decoder := json.NewDecoder(req.Body)
+	if err := decoder.Decode(&data); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Decoding error", err)
+		return
+	}
+	if data.Events == nil {
+		data.Events = []string{}
+	}
+
+	if err := validateWebhookEndpoint(data.URL, data.Events); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid endpoint: "+err.Error(), err)
+		return
+	}
+
+	endpoint, err := cfg.database.UpdateWebhookEndpoint(req.Context(), database.UpdateWebhookEndpointParams{
+		Url:     data.URL,
+		Events:  data.Events,
+		Enabled: sql.NullBool{Bool: data.Enabled != nil && *data.Enabled, Valid: data.Enabled != nil},
+		ID:      endpointID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No endpoint error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Updating endpoint error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, webhookEndpointFromDatabase(endpoint))
+}
+
+func (cfg *apiConfig) handlerDeleteWebhookEndpoint(w http.ResponseWriter, req *http.Request) {
+	if err := cfg.checkAdminKey(req); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid admin key", err)
+		return
+	}
+
+	endpointID, err := uuid.Parse(req.PathValue("endpointID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing endpointID error", err)
+		return
+	}
+
+	deleted, err := cfg.database.DeleteWebhookEndpoint(req.Context(), endpointID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Deleting endpoint error", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "No endpoint error", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlerGetWebhookDeliveries is the delivery log of an endpoint, newest first.
+func (cfg *apiConfig) handlerGetWebhookDeliveries(w http.ResponseWriter, req *http.Request) {
+	if err := cfg.checkAdminKey(req); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid admin key", err)
+		return
+	}
+
+	endpointID, err := uuid.Parse(req.PathValue("endpointID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing endpointID error", err)
+		return
+	}
+
+	if _, err := cfg.database.GetWebhookEndpoint(req.Context(), endpointID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No endpoint error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Getting endpoint error", err)
+		return
+	}
+
+	status := sql.NullString{}
+	switch filter := req.URL.Query().Get("status"); filter {
+	case "":
+	case deliveryPending, deliveryDelivered, deliveryFailed:
+		status = sql.NullString{String: filter, Valid: true}
+	default:
+		respondWithError(w, http.StatusBadRequest, "Invalid status", nil)
+		return
+	}
+
+	pageSize, err := parsePageSize(req.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := decodeCursor(req.URL.Query().Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	deliveries, err := cfg.database.ListWebhookDeliveries(req.Context(), database.ListWebhookDeliveriesParams{
+		EndpointID:      endpointID,
+		Status:          status,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        pageSize + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting deliveries error", err)
+		return
+	}
+
+	deliveries, nextCursor := nextPage(deliveries, pageSize, func(delivery database.WebhookDelivery) (time.Time, uuid.UUID) {
+		return delivery.CreatedAt, delivery.ID
+	})
+
+	deliveriesJSON := []WebhookDelivery{}
+	for _, delivery := range deliveries {
+		deliveriesJSON = append(deliveriesJSON, webhookDeliveryFromDatabase(delivery))
+	}
+
+	respondWithJSON(w, http.StatusOK, WebhookDeliveryPage{
+		Deliveries: deliveriesJSON,
+		NextCursor: nextCursor,
+	})
+}
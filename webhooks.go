@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/Mielecki/Chirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+const (
+	webhookPending   = "pending"
+	webhookProcessed = "processed"
+	webhookIgnored   = "ignored"
+	webhookFailed    = "failed"
+)
+
+type WebhookEvent struct {
+	ID          uuid.UUID       `json:"id"`
+	EventID     string          `json:"event_id"`
+	EventType   string          `json:"event_type"`
+	Payload     json.RawMessage `json:"payload"`
+	ReceivedAt  time.Time       `json:"received_at"`
+	ProcessedAt *time.Time      `json:"processed_at"`
+	Outcome     string          `json:"outcome"`
+	Error       *string         `json:"error"`
+	Attempts    int32           `json:"attempts"`
+}
+
+type WebhookEventPage struct {
+	Events     []WebhookEvent `json:"events"`
+	NextCursor *string        `json:"next_cursor"`
+}
+
+func webhookEventFromDatabase(event database.WebhookEvent) WebhookEvent {
+	eventJSON := WebhookEvent{
+		ID:         event.ID,
+		EventID:    event.EventID,
+		EventType:  event.EventType,
+		Payload:    event.Payload,
+		ReceivedAt: event.ReceivedAt,
+		Outcome:    event.Outcome,
+		Attempts:   event.Attempts,
+	}
+	if event.ProcessedAt.Valid {
+		eventJSON.ProcessedAt = &event.ProcessedAt.Time
+	}
+	if event.Error.Valid {
+		eventJSON.Error = &event.Error.String
+	}
+	return eventJSON
+}
+
+// processWebhookEvent applies a recorded event unless it already went through and returns its outcome.
+// The row lock makes concurrent deliveries of the same event wait and then find it done. A failure
+// rolls back whatever the event changed and is recorded on its own, so the event can be replayed.
+func (cfg *apiConfig) processWebhookEvent(ctx context.Context, eventID string) (string, error) {
+	tx, err := cfg.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	qtx := cfg.database.WithTx(tx)
+
+	event, err := qtx.GetWebhookEventForUpdate(ctx, eventID)
+	if err != nil {
+		return "", err
+	}
+	if event.Outcome == webhookProcessed || event.Outcome == webhookIgnored {
+		return event.Outcome, nil
+	}
+
+	outcome := webhookProcessed
+	payload := polkaEvent{}
+	err = json.Unmarshal(event.Payload, &payload)
+	if err == nil {
+		err = applyPolkaEvent(ctx, qtx, payload)
+	}
+	if errors.Is(err, errUnknownPolkaEvent) {
+		outcome = webhookIgnored
+		err = nil
+	}
+	if err != nil {
+		tx.Rollback()
+		if recordErr := cfg.database.CompleteWebhookEvent(ctx, database.CompleteWebhookEventParams{
+			Outcome: webhookFailed,
+			Error:   sql.NullString{String: err.Error(), Valid: true},
+			ID:      event.ID,
+		}); recordErr != nil {
+			// The event still reads as it did before, so callers mustn't report it as failed.
+			log.Printf("handling webhook event %s: %s", event.EventID, err)
+			return "", recordErr
+		}
+		return webhookFailed, err
+	}
+
+	if err := qtx.CompleteWebhookEvent(ctx, database.CompleteWebhookEventParams{
+		Outcome: outcome,
+		ID:      event.ID,
+	}); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return outcome, nil
+}
+
+func (cfg *apiConfig) handlerGetWebhookEvents(w http.ResponseWriter, req *http.Request) {
+	if err := cfg.checkAdminKey(req); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid admin key", err)
+		return
+	}
+
+	outcome := sql.NullString{}
+	switch filter := req.URL.Query().Get("outcome"); filter {
+	case "":
+	case webhookPending, webhookProcessed, webhookIgnored, webhookFailed:
+		outcome = sql.NullString{String: filter, Valid: true}
+	default:
+		respondWithError(w, http.StatusBadRequest, "Invalid outcome", nil)
+		return
+	}
+
+	pageSize, err := parsePageSize(req.URL.Query().Get("limit"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid limit", err)
+		return
+	}
+
+	cursorCreatedAt, cursorID, err := decodeCursor(req.URL.Query().Get("cursor"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid cursor", err)
+		return
+	}
+
+	events, err := cfg.database.ListWebhookEvents(req.Context(), database.ListWebhookEventsParams{
+		Outcome:         outcome,
+		CursorCreatedAt: cursorCreatedAt,
+		CursorID:        cursorID,
+		PageSize:        pageSize + 1,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting events error", err)
+		return
+	}
+
+	events, nextCursor := nextPage(events, pageSize, func(event database.WebhookEvent) (time.Time, uuid.UUID) {
+		return event.ReceivedAt, event.ID
+	})
+
+	eventsJSON := []WebhookEvent{}
+	for _, event := range events {
+		eventsJSON = append(eventsJSON, webhookEventFromDatabase(event))
+	}
+
+	respondWithJSON(w, http.StatusOK, WebhookEventPage{
+		Events:     eventsJSON,
+		NextCursor: nextCursor,
+	})
+}
+
+// handlerReplayWebhookEvent processes a failed event again and returns it with the new outcome.
+func (cfg *apiConfig) handlerReplayWebhookEvent(w http.ResponseWriter, req *http.Request) {
+	if err := cfg.checkAdminKey(req); err != nil {
+		respondWithError(w, http.StatusUnauthorized, "Invalid admin key", err)
+		return
+	}
+
+	id, err := uuid.Parse(req.PathValue("eventID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Parsing eventID error", err)
+		return
+	}
+
+	event, err := cfg.database.GetWebhookEvent(req.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			respondWithError(w, http.StatusNotFound, "No event error", err)
+			return
+		}
+		respondWithError(w, http.StatusInternalServerError, "Getting event error", err)
+		return
+	}
+	if event.Outcome == webhookProcessed || event.Outcome == webhookIgnored {
+		respondWithError(w, http.StatusConflict, "Event was already processed", nil)
+		return
+	}
+
+	// A failure to apply the event is recorded on it, which is what the response shows. Anything
+	// else means the outcome couldn't be recorded at all.
+	if outcome, err := cfg.processWebhookEvent(req.Context(), event.EventID); err != nil && outcome != webhookFailed {
+		respondWithError(w, http.StatusInternalServerError, "Replaying event error", err)
+		return
+	}
+
+	event, err = cfg.database.GetWebhookEvent(req.Context(), id)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Getting event error", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, webhookEventFromDatabase(event))
+}